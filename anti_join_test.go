@@ -0,0 +1,296 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+func BenchmarkAntiJoin(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	// xy.y is NULL for every tenth row, uv only holds even keys so half
+	// of xy is unmatched, and uv.v has a single NULL while uv.r is
+	// nullable but fully populated.
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int);")
+	s.WriteString("create table uv (u int primary key, v int, r int);")
+	insertRows(s, "xy", 101, func(i int) string {
+		if i%10 == 5 {
+			return fmt.Sprintf("%d, NULL", i)
+		}
+		return fmt.Sprintf("%d, %d", i, i)
+	})
+	insertRows(s, "uv", 51, func(i int) string {
+		u := 2 * i
+		if u == 50 {
+			return fmt.Sprintf("%d, NULL, %d", u, u)
+		}
+		return fmt.Sprintf("%d, %d, %d", u, u, u)
+	})
+	runSetup(e, ctx, s.String())
+
+	// NOT EXISTS and LEFT JOIN ... IS NULL agree regardless of NULLs.
+	// NOT IN only agrees with them when neither side is nullable.
+	equivalentQueries := [][]string{
+		{
+			"select * from xy where not exists (select * from uv where x = u)",
+			"select * from xy where x not in (select u from uv)",
+			"select xy.* from xy left join uv on x = u where u is null",
+		},
+		{
+			"select * from xy where not exists (select * from uv where y = v)",
+			"select xy.* from xy left join uv on y = v where u is null",
+		},
+	}
+	for _, qs := range equivalentQueries {
+		first := logAnalyzedPlan(e, ctx, qs[0])
+		for _, q := range qs[1:] {
+			checkEquivalent(ctx, q, first, logAnalyzedPlan(e, ctx, q))
+		}
+	}
+	notIn := "select * from xy where y not in (select v from uv)"
+	log.Printf("'%s' returns %d rows\n", notIn, len(sortedRowStrings(ctx, logAnalyzedPlan(e, ctx, notIn))))
+
+	xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	uvIndexable, ok := uv.(sql.IndexAddressableTable)
+	if !ok {
+		log.Fatalf("uv not index addressable")
+	}
+	uvIndexes, err := uvIndexable.GetIndexes(ctx)
+	uvPk := uvIndexes[0]
+
+	tests := []struct {
+		name string
+		pre  sql.Node
+		post sql.Node
+	}{
+		{
+			name: "not exists vs anti join",
+			pre: plan.NewFilter(
+				expression.NewNot(
+					plan.NewExistsSubquery(
+						plan.NewSubquery(
+							plan.NewFilter(
+								expression.NewEquals(
+									expression.NewGetField(0, types.Int64, "x", false),
+									expression.NewGetField(2, types.Int64, "u", false),
+								),
+								plan.NewResolvedTable(uv, db, nil),
+							),
+							"(select * from uv where x = u)"),
+					),
+				),
+				plan.NewResolvedTable(xy, db, nil),
+			),
+			post: plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				plan.NewResolvedTable(uv, db, nil),
+				plan.JoinTypeAnti,
+				expression.NewEquals(
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewGetField(2, types.Int64, "u", false),
+				),
+			),
+		},
+		{
+			name: "anti join vs anti lookup join",
+			pre: plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				plan.NewResolvedTable(uv, db, nil),
+				plan.JoinTypeAnti,
+				expression.NewEquals(
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewGetField(2, types.Int64, "u", false),
+				),
+			),
+			post: plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				mustIndexedAccessForResolvedTable(
+					plan.NewResolvedTable(uv, db, nil),
+					plan.NewLookupBuilder(
+						uvPk,
+						[]sql.Expression{
+							expression.NewGetField(0, types.Int64, "x", false),
+						},
+						[]bool{false},
+					),
+				),
+				plan.JoinTypeAntiLookup,
+				expression.NewEquals(
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewGetField(2, types.Int64, "u", false),
+				),
+			),
+		},
+		{
+			name: "not in vs anti hash join",
+			pre: plan.NewFilter(
+				plan.NewNotInSubquery(
+					expression.NewGetField(0, types.Int64, "x", false),
+					plan.NewSubquery(
+						plan.NewProject(
+							[]sql.Expression{
+								expression.NewGetField(2, types.Int64, "u", false),
+							},
+							plan.NewResolvedTable(uv, db, nil),
+						),
+						"(select u from uv)",
+					).WithCachedResults(),
+				),
+				plan.NewResolvedTable(xy, db, nil),
+			),
+			post: plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				plan.NewHashLookup(
+					plan.NewCachedResults(plan.NewResolvedTable(uv, db, nil)),
+					expression.NewGetField(0, types.Int64, "u", false),
+					expression.NewGetField(0, types.Int64, "x", false),
+				),
+				plan.JoinTypeAntiHash,
+				expression.NewEquals(
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewGetField(2, types.Int64, "u", false),
+				),
+			),
+		},
+		{
+			name: "left join is null vs anti lookup join",
+			pre: plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewGetField(1, types.Int64, "y", true),
+				},
+				plan.NewFilter(
+					expression.NewIsNull(
+						expression.NewGetField(2, types.Int64, "u", true),
+					),
+					plan.NewJoin(
+						plan.NewResolvedTable(xy, db, nil),
+						mustIndexedAccessForResolvedTable(
+							plan.NewResolvedTable(uv, db, nil),
+							plan.NewLookupBuilder(
+								uvPk,
+								[]sql.Expression{
+									expression.NewGetField(0, types.Int64, "x", false),
+								},
+								[]bool{false},
+							),
+						),
+						plan.JoinTypeLeftOuterLookup,
+						expression.NewEquals(
+							expression.NewGetField(0, types.Int64, "x", false),
+							expression.NewGetField(2, types.Int64, "u", false),
+						),
+					),
+				),
+			),
+			post: plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				mustIndexedAccessForResolvedTable(
+					plan.NewResolvedTable(uv, db, nil),
+					plan.NewLookupBuilder(
+						uvPk,
+						[]sql.Expression{
+							expression.NewGetField(0, types.Int64, "x", false),
+						},
+						[]bool{false},
+					),
+				),
+				plan.JoinTypeAntiLookup,
+				expression.NewEquals(
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewGetField(2, types.Int64, "u", false),
+				),
+			),
+		},
+		{
+			name: "nullable not exists vs anti hash join",
+			pre: plan.NewFilter(
+				expression.NewNot(
+					plan.NewExistsSubquery(
+						plan.NewSubquery(
+							plan.NewFilter(
+								expression.NewEquals(
+									expression.NewGetField(1, types.Int64, "y", true),
+									expression.NewGetField(3, types.Int64, "v", true),
+								),
+								plan.NewResolvedTable(uv, db, nil),
+							),
+							"(select * from uv where y = v)"),
+					),
+				),
+				plan.NewResolvedTable(xy, db, nil),
+			),
+			post: plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				plan.NewHashLookup(
+					plan.NewCachedResults(plan.NewResolvedTable(uv, db, nil)),
+					expression.NewGetField(1, types.Int64, "v", true),
+					expression.NewGetField(1, types.Int64, "y", true),
+				),
+				plan.JoinTypeAntiHash,
+				expression.NewEquals(
+					expression.NewGetField(1, types.Int64, "y", true),
+					expression.NewGetField(3, types.Int64, "v", true),
+				),
+			),
+		},
+		{
+			// A NULL on either side makes NOT IN evaluate to NULL rather
+			// than true, so the anti join condition has to reject those
+			// rows explicitly. The OR prevents a hash or lookup join.
+			name: "nullable not in vs null-aware anti join",
+			pre: plan.NewFilter(
+				plan.NewNotInSubquery(
+					expression.NewGetField(1, types.Int64, "y", true),
+					plan.NewSubquery(
+						plan.NewProject(
+							[]sql.Expression{
+								expression.NewGetField(4, types.Int64, "r", true),
+							},
+							plan.NewResolvedTable(uv, db, nil),
+						),
+						"(select r from uv)",
+					).WithCachedResults(),
+				),
+				plan.NewResolvedTable(xy, db, nil),
+			),
+			post: plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				plan.NewResolvedTable(uv, db, nil),
+				plan.JoinTypeAnti,
+				expression.NewOr(
+					expression.NewEquals(
+						expression.NewGetField(1, types.Int64, "y", true),
+						expression.NewGetField(4, types.Int64, "r", true),
+					),
+					expression.NewOr(
+						expression.NewIsNull(expression.NewGetField(1, types.Int64, "y", true)),
+						expression.NewIsNull(expression.NewGetField(4, types.Int64, "r", true)),
+					),
+				),
+			),
+		},
+	}
+
+	for _, bb := range tests {
+		checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+	}
+}
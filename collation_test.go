@@ -0,0 +1,83 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// BenchmarkCollation stores the same mixed case strings in VARCHAR
+// columns with binary, case insensitive and latin1 collations, and
+// compares lookups, range scans, sorts, grouping and joins on them.
+// Case insensitive collations treat "Ber", "ber" and "BER" as one value,
+// so their results are larger or have fewer groups where the engine
+// applies the collation. DISTINCT and hash joins do not apply it, which
+// is an engine bug.
+func BenchmarkCollation(b *testing.B) {
+	words := likeVocabulary(500)
+	// value returns one of 500 pairs of words in one of three cases, so
+	// there are 1500 distinct values but only 500 ignoring case
+	value := func(i int) string {
+		v := fmt.Sprintf("%s %s", words[i%500], words[i*7%500])
+		switch i / 500 % 3 {
+		case 1:
+			v = strings.ToUpper(v[:1]) + v[1:]
+		case 2:
+			v = strings.ToUpper(v)
+		}
+		return v
+	}
+	lo, hi := words[100], words[200]
+
+	bin, ci, latin1 := "varchar(64) collate utf8mb4_0900_bin", "varchar(64) collate utf8mb4_0900_ai_ci", "varchar(64) character set latin1"
+	// caseless expects the case insensitive collations to return other
+	// rows than the binary one
+	caseless := func(reason string) map[string]string {
+		return map[string]string{ci: reason, latin1: reason}
+	}
+	matchesCases := caseless("the case insensitive collation matches all three spellings")
+	sortsTogether := caseless("the case insensitive collation sorts the three spellings of a value next to each other")
+
+	queries := []columnTypeQuery{
+		{name: "point lookup", sql: func(string) string {
+			return fmt.Sprintf("select count(*) from t where v = '%s'", value(1))
+		}, differs: matchesCases},
+		{name: "range", sql: func(string) string {
+			return fmt.Sprintf("select count(*) from t where v >= '%s' and v < '%s'", lo, hi)
+		}, differs: matchesCases},
+		{name: "order by index", sql: func(string) string {
+			return "select v from t order by v"
+		}, ordered: true, differs: sortsTogether},
+		{name: "order by", sql: func(string) string {
+			return "select w from t order by w"
+		}, ordered: true, differs: sortsTogether},
+		{name: "group by", sql: func(string) string {
+			return "select w, count(*) from t group by w"
+		}, differs: caseless("the case insensitive collation groups the three spellings together")},
+		{name: "distinct", sql: func(string) string {
+			return "select distinct w from t"
+		}, bugs: caseless("distinct compares the bytes of the string, and returns all three spellings instead of one")},
+		{name: "hash join", sql: func(string) string {
+			return "select count(*) from t join u on t.w = u.w"
+		}, bugs: caseless("the hash join hashes the bytes of the string, and finds at most one match that differs in case")},
+		{name: "lookup join", sql: func(string) string {
+			return "select count(*) from u join t on u.w = t.v"
+		}, differs: matchesCases},
+	}
+
+	runColumnTypeComparison(b, []string{bin, ci, latin1}, queries, func(typ string) string {
+		// v is indexed and w holds the same values without an index. u
+		// holds the first 1000 values of t.
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString(fmt.Sprintf("create table t (id int primary key, v %s, w %s, key(v));", typ, typ))
+		s.WriteString(fmt.Sprintf("create table u (id int primary key, w %s);", typ))
+		insertRows(s, "t", columnTypeRows, func(i int) string {
+			return fmt.Sprintf("%d, '%s', '%s'", i, value(i), value(i))
+		})
+		insertRows(s, "u", 1000, func(i int) string {
+			return fmt.Sprintf("%d, '%s'", i, value(i))
+		})
+		return s.String()
+	})
+}
@@ -0,0 +1,296 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// columnTypeRows is the number of rows in every column type fixture.
+const columnTypeRows = 20000
+
+// columnTypeQuery is one query of a column type comparison. |sql| maps a
+// column type name to the query text for a table of that type, so each
+// type can use literals and expressions of its own.
+//
+// Every type must return the same rows as the first type, compared as
+// columnTypeRowStrings renders them. |normalize| converts a rendered row
+// of type |typ| further, for types that store the same value in other
+// units, and is skipped if nil. Rows are compared as a set unless
+// |ordered| is set, for queries whose ORDER BY is part of the result.
+// |differs| maps the types whose rows are expected to differ from the
+// first type's to the reason. An ordered query must still return the
+// same rows in those types, only in another order. |bugs| maps the
+// types for which the engine returns wrong rows to the bug, and their
+// rows are not checked.
+type columnTypeQuery struct {
+	name      string
+	sql       func(typ string) string
+	normalize func(typ string, row []string) []string
+	ordered   bool
+	differs   map[string]string
+	bugs      map[string]string
+}
+
+// BenchmarkNumericType stores the same money amounts as BIGINT cents,
+// DECIMAL(12,2) and DOUBLE dollars, and compares scans, filters, SUM
+// aggregates and joins on the column.
+func BenchmarkNumericType(b *testing.B) {
+	// every amount is distinct, from 0.00 to 9999.99
+	cents := func(i int) int { return (i * 7919) % 1000000 }
+	literal := map[string]func(cents int) string{
+		"bigint":        func(c int) string { return fmt.Sprintf("%d", c) },
+		"decimal(12,2)": func(c int) string { return fmt.Sprintf("%d.%02d", c/100, c%100) },
+		"double":        func(c int) string { return fmt.Sprintf("%d.%02d", c/100, c%100) },
+	}
+	// dollars renders the amount in the first column in dollars, rounded
+	// to cents so the sum of doubles matches the exact sums
+	dollars := func(typ string, row []string) []string {
+		f, err := strconv.ParseFloat(row[0], 64)
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+		if typ == "bigint" {
+			f /= 100
+		}
+		row[0] = fmt.Sprintf("%.2f", f)
+		return row
+	}
+
+	queries := []columnTypeQuery{
+		{name: "scan", sql: func(string) string { return "select v from t" }, normalize: dollars},
+		{name: "filter", sql: func(typ string) string {
+			return fmt.Sprintf("select count(*) from t where v > %s", literal[typ](500000))
+		}},
+		{name: "filter double", sql: func(typ string) string {
+			if typ == "bigint" {
+				return "select count(*) from t where v > 500000e0"
+			}
+			return "select count(*) from t where v > 5000e0"
+		}},
+		{name: "sum", sql: func(string) string { return "select sum(v) from t" }, normalize: dollars},
+		{name: "join", sql: func(string) string { return "select count(*) from t join u on t.v = u.v" }},
+	}
+
+	runColumnTypeComparison(b, []string{"bigint", "decimal(12,2)", "double"}, queries, func(typ string) string {
+		// t has no index on v, so filters and aggregates evaluate every
+		// value. u holds the same values with an index for the join.
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString(fmt.Sprintf("create table t (id int primary key, v %s);", typ))
+		s.WriteString(fmt.Sprintf("create table u (id int primary key, v %s, key(v));", typ))
+		for _, table := range []string{"t", "u"} {
+			insertRows(s, table, columnTypeRows, func(i int) string {
+				return fmt.Sprintf("%d, %s", i, literal[typ](cents(i)))
+			})
+		}
+		return s.String()
+	})
+}
+
+// BenchmarkTemporalType stores the same instants as DATETIME, TIMESTAMP
+// and BIGINT seconds since the epoch, and compares index range
+// predicates and GROUP BY date buckets.
+func BenchmarkTemporalType(b *testing.B) {
+	// rows are 37 minutes apart, about 39 per day for 514 days
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	instant := func(i int) time.Time { return start.Add(time.Duration(i*37) * time.Minute) }
+	literal := func(typ string, t time.Time) string {
+		if typ == "bigint" {
+			return fmt.Sprintf("%d", t.Unix())
+		}
+		return fmt.Sprintf("'%s'", t.Format("2006-01-02 15:04:05"))
+	}
+	march, april := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	// unix renders the bigint in the first column, |unit| seconds since
+	// the epoch, as a time in |layout|
+	unix := func(unit int64, layout string) func(typ string, row []string) []string {
+		return func(typ string, row []string) []string {
+			if typ != "bigint" {
+				return row
+			}
+			v, err := strconv.ParseInt(row[0], 10, 64)
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+			row[0] = time.Unix(v*unit, 0).UTC().Format(layout)
+			return row
+		}
+	}
+
+	queries := []columnTypeQuery{
+		{name: "scan", sql: func(string) string { return "select ts from t" }, normalize: unix(1, columnTypeTime)},
+		{name: "month range", sql: func(typ string) string {
+			return fmt.Sprintf("select count(*) from t where ts >= %s and ts < %s", literal(typ, march), literal(typ, april))
+		}},
+		{name: "month cast", sql: func(typ string) string {
+			if typ == "bigint" {
+				return fmt.Sprintf("select count(*) from t where ts >= %s and ts < %s", literal(typ, march), literal(typ, april))
+			}
+			return fmt.Sprintf("select count(*) from t where ts >= cast(%s as datetime) and ts < cast(%s as datetime)", literal(typ, march), literal(typ, april))
+		}},
+		{name: "month between", sql: func(typ string) string {
+			return fmt.Sprintf("select count(*) from t where ts between %s and %s", literal(typ, march), literal(typ, april.Add(-time.Second)))
+		}},
+		{name: "group by day", sql: func(typ string) string {
+			if typ == "bigint" {
+				return "select ts div 86400, count(*) from t group by ts div 86400"
+			}
+			return "select date(ts), count(*) from t group by date(ts)"
+		}, normalize: unix(86400, "2006-01-02")},
+		{name: "group by month", sql: func(typ string) string {
+			if typ == "bigint" {
+				return "select year(from_unixtime(ts)), month(from_unixtime(ts)), count(*) from t group by 1, 2"
+			}
+			return "select year(ts), month(ts), count(*) from t group by 1, 2"
+		}},
+	}
+
+	runColumnTypeComparison(b, []string{"datetime", "timestamp", "bigint"}, queries, func(typ string) string {
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString(fmt.Sprintf("create table t (id int primary key, ts %s, key(ts));", typ))
+		insertRows(s, "t", columnTypeRows, func(i int) string {
+			return fmt.Sprintf("%d, %s", i, literal(typ, instant(i)))
+		})
+		return s.String()
+	})
+}
+
+// runColumnTypeComparison loads the tables from |setup| once for every
+// type in |typs|, times the analyzer's plan for each of |queries|, and
+// logs ns/op and the result size per query and type. It fails if a type
+// returns different rows than the first type, unless the query expects
+// it or the engine is known to return wrong rows for that type. Queries
+// whose result is a single row log that row, unconverted. The row data
+// size of table t is logged per type.
+func runColumnTypeComparison(b *testing.B, typs []string, queries []columnTypeQuery, setup func(typ string) string) {
+	width := 16
+	for _, typ := range typs {
+		if len(typ) > width {
+			width = len(typ)
+		}
+	}
+	report := &strings.Builder{}
+	report.WriteString(fmt.Sprintf("%-16s %-*s %12s %6s  %s\n", "query", width, "type", "ns/op", "rows", "result"))
+	storage := &strings.Builder{}
+	storage.WriteString(fmt.Sprintf("%-*s %8s %10s\n", width, "type", "nodes", "t bytes"))
+	// want holds the rows of the first type for each query
+	want := make(map[string][]string)
+	for _, typ := range typs {
+		e, ctx := setupMemDB()
+		runSetup(e, ctx, setup(typ))
+		t, _, err := e.Analyzer.Catalog.Table(ctx, "test", "t")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+		nodes, bytes := tableStorage(ctx, t)
+		storage.WriteString(fmt.Sprintf("%-*s %8d %10d\n", width, typ, nodes, bytes))
+		for _, q := range queries {
+			n := logAnalyzedPlan(e, ctx, q.sql(typ))
+			rows := columnTypeRowStrings(ctx, n, typ, q.normalize, q.ordered)
+			name := fmt.Sprintf("%s %s", q.name, typ)
+			if typ == typs[0] {
+				want[q.name] = rows
+			} else if bug, ok := q.bugs[typ]; ok {
+				log.Printf("%s: engine bug, %d rows are not checked: %s\n", name, len(rows), bug)
+			} else if reason, ok := q.differs[typ]; ok {
+				if strings.Join(rows, "\n") == strings.Join(want[q.name], "\n") {
+					log.Fatalf("%s: rows are the same as %s's, expected them to differ: %s\n", name, typs[0], reason)
+				}
+				log.Printf("%s: rows differ from %s, expected: %s\n", name, typs[0], reason)
+				if q.ordered {
+					wantSet, gotSet := append([]string{}, want[q.name]...), append([]string{}, rows...)
+					sort.Strings(wantSet)
+					sort.Strings(gotSet)
+					checkSameRows(name, wantSet, gotSet)
+				}
+			} else {
+				checkSameRows(name, want[q.name], rows)
+			}
+			// the result is shown as the engine returns it
+			result := ""
+			if raw := sortedRowStrings(ctx, n); len(raw) == 1 {
+				result = raw[0]
+			}
+			nsOp := runOneBench(b, ctx, name, n)
+			report.WriteString(fmt.Sprintf("%-16s %-*s %12d %6d  %s\n", q.name, width, typ, nsOp, len(rows), result))
+		}
+	}
+	log.Print(report.String())
+	log.Print(storage.String())
+}
+
+// columnTypeRowStrings executes |n| and returns its rows, with every
+// value rendered the same way whatever its column type: ENUM and SET
+// values as their members, numbers in decimal notation without trailing
+// zeros, and times as DATETIME literals. |normalize| then converts each
+// row of type |typ| if it is not nil. The rows are sorted unless
+// |ordered| is set.
+func columnTypeRowStrings(ctx *sql.Context, n sql.Node, typ string, normalize func(typ string, row []string) []string, ordered bool) []string {
+	sch := n.Schema()
+	iter, err := n.RowIter(ctx, nil)
+	if err != nil {
+		log.Fatalf("iter query error '%s': %s\n", sql.DebugString(n), err)
+	}
+	rows, err := sql.RowIterToRows(ctx, sch, iter)
+	if err != nil {
+		log.Fatalf("executing query '%s': %s\n", sql.DebugString(n), err)
+	}
+	ret := make([]string, len(rows))
+	for i, r := range rows {
+		row := make([]string, len(r))
+		for j, v := range r {
+			row[j] = columnTypeValue(sch[j].Type, v)
+		}
+		if normalize != nil {
+			row = normalize(typ, row)
+		}
+		ret[i] = fmt.Sprint(row)
+	}
+	if !ordered {
+		sort.Strings(ret)
+	}
+	return ret
+}
+
+// columnTypeValue renders |v|, a value of type |t|, for
+// columnTypeRowStrings.
+func columnTypeValue(t sql.Type, v interface{}) string {
+	if v == nil {
+		return "NULL"
+	}
+	switch t := t.(type) {
+	case sql.EnumType:
+		if s, ok := t.At(int(v.(uint16))); ok {
+			return s
+		}
+	case sql.SetType:
+		s, err := t.BitsToString(v.(uint64))
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+		return s
+	}
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(columnTypeTime)
+	}
+	f, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// columnTypeTime is the layout of rendered times, a DATETIME literal.
+const columnTypeTime = "2006-01-02 15:04:05"
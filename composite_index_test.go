@@ -0,0 +1,118 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+// BenchmarkCompositeIndex reads the same rows through composite indexes
+// (a,b,c) and (b,a,c) with ranges that constrain different numbers of
+// leading columns. Every read keeps the full predicate as a filter, so
+// each pair returns the same rows and only the range differs.
+func BenchmarkCompositeIndex(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	// a has 100 values and b has 50, independent of each other
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table t (id int primary key, a int, b int, c int, d int, key abc (a,b,c), key bac (b,a,c));")
+	insertRows(s, "t", 20000, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d, %d", i, i%100, (i/100)%50, i%7, i)
+	})
+	runSetup(e, ctx, s.String())
+
+	t, db, err := e.Analyzer.Catalog.Table(ctx, "test", "t")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	tIndexable, ok := t.(sql.IndexAddressableTable)
+	if !ok {
+		log.Fatalf("t not index addressable")
+	}
+	tIndexes, err := tIndexable.GetIndexes(ctx)
+	abcIdx := tIndexes[1]
+	bacIdx := tIndexes[2]
+
+	a := expression.NewGetField(1, types.Int64, "a", true)
+	bCol := expression.NewGetField(2, types.Int64, "b", true)
+	eq := func(col sql.Expression, v int) sql.Expression {
+		return expression.NewEquals(col, expression.NewLiteral(v, types.Int64))
+	}
+	between := func(col sql.Expression, lo, hi int) sql.Expression {
+		return expression.NewBetween(col, expression.NewLiteral(lo, types.Int64), expression.NewLiteral(hi, types.Int64))
+	}
+	point := func(v int) sql.RangeColumnExpr {
+		return sql.ClosedRangeColumnExpr(v, v, types.Int64)
+	}
+	all := sql.AllRangeColumnExpr(types.Int64)
+
+	scan := func(filter sql.Expression) sql.Node {
+		return plan.NewFilter(filter, plan.NewResolvedTable(t, db, nil))
+	}
+	read := func(filter sql.Expression, idx sql.Index, r sql.Range) sql.Node {
+		return plan.NewFilter(filter, mustStaticIndexedAccessForResolvedTable(
+			plan.NewResolvedTable(t, db, nil),
+			sql.IndexLookup{
+				Index:  idx,
+				Ranges: sql.RangeCollection{r},
+			}))
+	}
+
+	aEq := eq(a, 5)
+	abEq := expression.NewAnd(eq(a, 5), eq(bCol, 7))
+	aRangeBEq := expression.NewAnd(between(a, 10, 19), eq(bCol, 7))
+	bEq := eq(bCol, 7)
+
+	for _, q := range []string{
+		"select * from t where a = 5",
+		"select * from t where a = 5 and b = 7",
+		"select * from t where a between 10 and 19 and b = 7",
+		"select * from t where b = 7",
+	} {
+		log.Printf("'%s' returns %d rows\n", q, len(sortedRowStrings(ctx, logAnalyzedPlan(e, ctx, q))))
+	}
+
+	tests := []struct {
+		name string
+		pre  sql.Node
+		post sql.Node
+	}{
+		{
+			name: "a equality scan vs prefix",
+			pre:  scan(aEq),
+			post: read(aEq, abcIdx, sql.Range{point(5), all, all}),
+		},
+		{
+			name: "a b equality one column vs two column prefix",
+			pre:  read(abEq, abcIdx, sql.Range{point(5), all, all}),
+			post: read(abEq, abcIdx, sql.Range{point(5), point(7), all}),
+		},
+		{
+			name: "a range b equality range first vs equality first",
+			pre:  read(aRangeBEq, abcIdx, sql.Range{sql.ClosedRangeColumnExpr(10, 19, types.Int64), point(7), all}),
+			post: read(aRangeBEq, bacIdx, sql.Range{point(7), sql.ClosedRangeColumnExpr(10, 19, types.Int64), all}),
+		},
+		{
+			name: "b equality no prefix vs prefix",
+			pre:  read(bEq, abcIdx, sql.Range{all, point(7), all}),
+			post: read(bEq, bacIdx, sql.Range{point(7), all, all}),
+		},
+		{
+			name: "b equality scan vs no prefix",
+			pre:  scan(bEq),
+			post: read(bEq, abcIdx, sql.Range{all, point(7), all}),
+		},
+	}
+
+	for _, bb := range tests {
+		checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+	}
+}
@@ -0,0 +1,140 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+func BenchmarkDerivedTable(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int, z int, w int);")
+	insertRows(s, "xy", 1000, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+	})
+	runSetup(e, ctx, s.String())
+
+	for _, q := range []string{
+		"select * from (select * from xy) sq where sq.x = 0",
+		"select * from (select * from (select * from xy) a) b where b.x = 0",
+		"select sq.x from (select * from xy) sq",
+		"select b.x from (select * from (select * from xy) a) b",
+		"select * from (select * from xy limit 10) sq where sq.x = 500",
+		"select * from (select x, count(*) from xy group by x) sq where sq.x = 0",
+	} {
+		log.Printf("'%s' returns %d rows\n", q, len(sortedRowStrings(ctx, logAnalyzedPlan(e, ctx, q))))
+	}
+
+	xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	xyIndexable, ok := xy.(sql.IndexAddressableTable)
+	if !ok {
+		log.Fatalf("xy not index addressable")
+	}
+	xyIndexes, err := xyIndexable.GetIndexes(ctx)
+	xyPk := xyIndexes[0]
+
+	tests := []struct {
+		name string
+		pre  sql.Node
+		post sql.Node
+	}{
+		{
+			name: "derived table filter pushdown",
+			pre: plan.NewFilter(
+				expression.NewEquals(
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewLiteral(0, types.Int64),
+				),
+				plan.NewSubqueryAlias("sq", "select * from xy",
+					plan.NewResolvedTable(xy, db, nil),
+				),
+			),
+			post: plan.NewSubqueryAlias("sq", "select * from xy where x = 0",
+				mustStaticIndexedAccessForResolvedTable(
+					plan.NewResolvedTable(xy, db, nil),
+					sql.IndexLookup{
+						Index: xyPk,
+						Ranges: sql.RangeCollection{
+							sql.Range{sql.ClosedRangeColumnExpr(0, 0, types.Int64)},
+						},
+					}),
+			),
+		},
+		{
+			name: "nested derived table filter pushdown",
+			pre: plan.NewFilter(
+				expression.NewEquals(
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewLiteral(0, types.Int64),
+				),
+				plan.NewSubqueryAlias("b", "select * from (select * from xy) a",
+					plan.NewSubqueryAlias("a", "select * from xy",
+						plan.NewResolvedTable(xy, db, nil),
+					),
+				),
+			),
+			post: plan.NewSubqueryAlias("b", "select * from (select * from xy where x = 0) a",
+				plan.NewSubqueryAlias("a", "select * from xy where x = 0",
+					mustStaticIndexedAccessForResolvedTable(
+						plan.NewResolvedTable(xy, db, nil),
+						sql.IndexLookup{
+							Index: xyPk,
+							Ranges: sql.RangeCollection{
+								sql.Range{sql.ClosedRangeColumnExpr(0, 0, types.Int64)},
+							},
+						}),
+				),
+			),
+		},
+		{
+			name: "derived table projection pruning",
+			pre: plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetField(0, types.Int64, "x", false),
+				},
+				plan.NewSubqueryAlias("sq", "select * from xy",
+					plan.NewResolvedTable(xy, db, nil),
+				),
+			),
+			post: plan.NewSubqueryAlias("sq", "select x from xy",
+				plan.NewResolvedTable(xy.(*sqle.AlterableDoltTable).WithProjections([]string{"x"}), db, nil),
+			),
+		},
+		{
+			name: "nested derived table projection pruning",
+			pre: plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetField(0, types.Int64, "x", false),
+				},
+				plan.NewSubqueryAlias("b", "select * from (select * from xy) a",
+					plan.NewSubqueryAlias("a", "select * from xy",
+						plan.NewResolvedTable(xy, db, nil),
+					),
+				),
+			),
+			post: plan.NewSubqueryAlias("b", "select x from (select x from xy) a",
+				plan.NewSubqueryAlias("a", "select x from xy",
+					plan.NewResolvedTable(xy.(*sqle.AlterableDoltTable).WithProjections([]string{"x"}), db, nil),
+				),
+			),
+		},
+	}
+
+	for _, bb := range tests {
+		checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+	}
+}
@@ -0,0 +1,140 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+// enumStatuses are the values of the status column, with the percent of
+// rows holding each one.
+var enumStatuses = []struct {
+	name string
+	pct  int
+}{
+	{name: "pending", pct: 10},
+	{name: "active", pct: 60},
+	{name: "suspended", pct: 10},
+	{name: "closed", pct: 15},
+	{name: "deleted", pct: 5},
+}
+
+// enumFlags are the members of the flags column.
+var enumFlags = []string{"read", "write", "admin", "billing", "audit"}
+
+// BenchmarkEnum compares an ENUM status column against a VARCHAR column
+// holding the same strings.
+func BenchmarkEnum(b *testing.B) {
+	status := func(i int) string {
+		p := i * 37 % 100
+		for _, s := range enumStatuses {
+			if p < s.pct {
+				return s.name
+			}
+			p -= s.pct
+		}
+		log.Fatalf("percents do not add up to 100")
+		return ""
+	}
+	names := make([]string, len(enumStatuses))
+	for i, s := range enumStatuses {
+		names[i] = fmt.Sprintf("'%s'", s.name)
+	}
+
+	queries := []columnTypeQuery{
+		{name: "scan", sql: func(string) string { return "select s from t" }},
+		{name: "filter", sql: func(string) string { return "select count(*) from t where s = 'suspended'" }},
+		{name: "filter in", sql: func(string) string { return "select count(*) from t where s in ('closed', 'deleted')" }},
+		{name: "group by", sql: func(string) string { return "select s, count(*) from t group by s" }},
+		{name: "order by", sql: func(string) string { return "select s from t order by s" }},
+	}
+
+	runColumnTypeComparison(b, []string{
+		fmt.Sprintf("enum(%s)", strings.Join(names, ",")),
+		"varchar(16)",
+	}, queries, func(typ string) string {
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString(fmt.Sprintf("create table t (id int primary key, s %s);", typ))
+		insertRows(s, "t", columnTypeRows, func(i int) string {
+			return fmt.Sprintf("%d, '%s'", i, status(i))
+		})
+		return s.String()
+	})
+}
+
+// BenchmarkSet compares a SET column against a VARCHAR column holding the
+// same comma separated lists.
+func BenchmarkSet(b *testing.B) {
+	// flags returns the members of mask |i| mod 32 in declaration order,
+	// which is how a SET value is rendered
+	flags := func(i int) string {
+		var members []string
+		for f, name := range enumFlags {
+			if i&(1<<f) != 0 {
+				members = append(members, name)
+			}
+		}
+		return strings.Join(members, ",")
+	}
+	names := make([]string, len(enumFlags))
+	for i, f := range enumFlags {
+		names[i] = fmt.Sprintf("'%s'", f)
+	}
+
+	queries := []columnTypeQuery{
+		{name: "scan", sql: func(string) string { return "select f from t" }},
+		{name: "filter equal", sql: func(string) string { return "select count(*) from t where f = 'read,admin'" }},
+		{name: "has admin", sql: func(typ string) string {
+			// find_in_set is not supported, so a SET is tested by its bit
+			// and a list by matching the member between commas
+			if strings.HasPrefix(typ, "set") {
+				return "select count(*) from t where f & 4 > 0"
+			}
+			return "select count(*) from t where concat(',', f, ',') like '%,admin,%'"
+		}},
+		{name: "like", sql: func(string) string {
+			return "select count(*) from t where f like '%admin%'"
+		}, differs: map[string]string{"varchar(64)": "LIKE matches the bitmask of a SET instead of its members, so the SET query finds no rows"}},
+		{name: "group by", sql: func(string) string { return "select f, count(*) from t group by f" }},
+	}
+
+	runColumnTypeComparison(b, []string{
+		fmt.Sprintf("set(%s)", strings.Join(names, ",")),
+		"varchar(64)",
+	}, queries, func(typ string) string {
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString(fmt.Sprintf("create table t (id int primary key, f %s);", typ))
+		insertRows(s, "t", columnTypeRows, func(i int) string {
+			return fmt.Sprintf("%d, '%s'", i, flags(i*7%32))
+		})
+		return s.String()
+	})
+}
+
+// BenchmarkBit compares BIT(1) and BOOLEAN flags against TINYINT.
+// BOOLEAN is an alias for TINYINT(1).
+func BenchmarkBit(b *testing.B) {
+	queries := []columnTypeQuery{
+		{name: "scan", sql: func(string) string { return "select b from t" }},
+		{name: "filter", sql: func(string) string { return "select count(*) from t where b = 1" }},
+		{name: "group by", sql: func(string) string { return "select b, count(*) from t group by b" }},
+		{name: "sum", sql: func(string) string { return "select sum(b) from t" }},
+	}
+
+	runColumnTypeComparison(b, []string{"bit(1)", "boolean", "tinyint"}, queries, func(typ string) string {
+		// a third of the flags are set
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString(fmt.Sprintf("create table t (id int primary key, b %s);", typ))
+		insertRows(s, "t", columnTypeRows, func(i int) string {
+			if i%3 == 0 {
+				return fmt.Sprintf("%d, 1", i)
+			}
+			return fmt.Sprintf("%d, 0", i)
+		})
+		return s.String()
+	})
+}
@@ -0,0 +1,142 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"log"
+	"strings"
+	"testing"
+)
+
+// fkParents is the number of rows in each foreign key parent table, and
+// fkFanout is the number of children of every row in a cascade chain.
+const (
+	fkParents = 250
+	fkFanout  = 4
+)
+
+// BenchmarkForeignKey measures writes to a child table with and without
+// a foreign key to its parent, bulk loads with foreign_key_checks
+// disabled, and deletes that cascade through chains of 1 to 3 tables.
+func BenchmarkForeignKey(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	// c and fk hold the same rows, and both have an index on x. Only fk
+	// declares that x references xy.
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int);")
+	s.WriteString("create table c (id int primary key, x int, z int, key(x));")
+	s.WriteString("create table fk (id int primary key, x int, z int, foreign key (x) references xy(x));")
+	insertRows(s, "xy", 1000, func(i int) string {
+		return fmt.Sprintf("%d, %d", i, i)
+	})
+	for _, table := range []string{"c", "fk"} {
+		insertRows(s, table, 1000, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d", i, i, i)
+		})
+	}
+	runSetup(e, ctx, s.String())
+
+	// insert returns an insert of |n| new rows into |table| that
+	// reference every parent in turn
+	insert := func(table string, n int) string {
+		s := &strings.Builder{}
+		insertRows(s, table, n, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d", 100000+i, i%1000, i)
+		})
+		return strings.TrimSuffix(s.String(), ";\n")
+	}
+	undo := func(table string) string {
+		return fmt.Sprintf("delete from %s where id >= 100000", table)
+	}
+	// 999 - x maps every parent to another parent, so the update can
+	// repeat without a reset
+	update := func(table string) string {
+		return fmt.Sprintf("update %s set x = 999 - x where id < 100", table)
+	}
+	reinsert := func(table string) string {
+		s := &strings.Builder{}
+		insertRows(s, table, 100, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d", i, i, i)
+		})
+		return fmt.Sprintf("delete from %s where id < 100; ", table) + strings.TrimSuffix(s.String(), ";\n")
+	}
+
+	runQueryComparison(b, e, ctx, "insert 100 rows no fk vs fk", insert("c", 100), insert("fk", 100), undo("c")+"; "+undo("fk"))
+	runQueryComparison(b, e, ctx, "update 100 rows no fk vs fk", update("c"), update("fk"), "")
+	runQueryComparison(b, e, ctx, "delete 100 rows no fk vs fk", "delete from c where id < 100", "delete from fk where id < 100", reinsert("c")+"; "+reinsert("fk"))
+
+	// a bulk load checks every row against the parent unless the session
+	// turns the checks off
+	runQueryComparison(b, e, ctx, "bulk insert 10000 rows no fk vs fk", insert("c", 10000), insert("fk", 10000), undo("c")+"; "+undo("fk"))
+	runSetup(e, ctx, "set foreign_key_checks = 0")
+	runOneQueryBench(b, e, ctx, "bulk insert 10000 rows fk foreign_key_checks=0", insert("fk", 10000), undo("fk"))
+	runSetup(e, ctx, "set foreign_key_checks = 1")
+
+	// rows written with the checks off are not checked when they are
+	// turned back on, but any later update of an orphaned row fails, even
+	// one that does not set x
+	runSetup(e, ctx, "set foreign_key_checks = 0; insert into fk values (200000, 5000, 0); set foreign_key_checks = 1")
+	orphanUpdate := "update fk set z = z + 1 where id = 200000"
+	sch, iter, err := e.Query(ctx, orphanUpdate)
+	if err == nil {
+		_, err = sql.RowIterToRows(ctx, sch, iter)
+	}
+	if err == nil {
+		log.Fatalf("'%s' updated an orphaned row\n", orphanUpdate)
+	}
+	log.Printf("'%s' fails: %s\n", orphanUpdate, err)
+	runSetup(e, ctx, "delete from fk where id = 200000")
+
+	for depth := 0; depth <= 3; depth++ {
+		e, ctx := setupMemDB()
+		runSetup(e, ctx, "use test;"+foreignKeyChain(depth, fkParents, fkFanout))
+
+		// deleting 10 parents deletes fkFanout children of each deleted
+		// row at every level
+		del := "delete from t0 where id < 10"
+		reset := foreignKeyChainRows(depth, 10, fkFanout)
+		count := func() interface{} {
+			return runQuery(e, ctx, fmt.Sprintf("select count(*) from t%d", depth))[0][0]
+		}
+		before := count()
+		runQuery(e, ctx, del)
+		log.Printf("depth %d: deleting 10 parents leaves %v of %v rows in t%d\n", depth, count(), before, depth)
+		runSetup(e, ctx, reset)
+		runOneQueryBench(b, e, ctx, fmt.Sprintf("delete 10 parents cascade depth %d", depth), del, reset)
+	}
+}
+
+// foreignKeyChain returns statements that create tables t0 to t|depth|
+// and fill them. t0 has |parents| rows, and every later table has
+// |fanout| rows for each row of the table before it, with a foreign key
+// to it that cascades deletes. Row i of table t(k+1) references row
+// i / |fanout| of tk.
+func foreignKeyChain(depth, parents, fanout int) string {
+	s := &strings.Builder{}
+	s.WriteString("create table t0 (id int primary key, z int);")
+	for k := 1; k <= depth; k++ {
+		s.WriteString(fmt.Sprintf("create table t%d (id int primary key, pid int, z int, foreign key (pid) references t%d(id) on delete cascade);", k, k-1))
+	}
+	s.WriteString(foreignKeyChainRows(depth, parents, fanout))
+	return s.String()
+}
+
+// foreignKeyChainRows returns inserts of the rows descending from the
+// first |parents| rows of t0 in a chain built by foreignKeyChain,
+// parents first.
+func foreignKeyChainRows(depth, parents, fanout int) string {
+	s := &strings.Builder{}
+	insertRows(s, "t0", parents, func(i int) string {
+		return fmt.Sprintf("%d, %d", i, i)
+	})
+	n := parents
+	for k := 1; k <= depth; k++ {
+		n *= fanout
+		insertRows(s, fmt.Sprintf("t%d", k), n, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d", i, i/fanout, i)
+		})
+	}
+	return strings.TrimSuffix(s.String(), ";\n")
+}
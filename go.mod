@@ -5,6 +5,8 @@ go 1.19
 require (
 	github.com/dolthub/dolt/go v0.40.5-0.20230313214220-75337275f725
 	github.com/dolthub/go-mysql-server v0.14.1-0.20230313174429-2213193d6b8b
+	github.com/dolthub/vitess v0.0.0-20230310225942-1731d057dc71
+	github.com/go-sql-driver/mysql v1.6.0
 )
 
 require (
@@ -19,13 +21,11 @@ require (
 	github.com/denisbrodbeck/machineid v1.0.1 // indirect
 	github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi v0.0.0-20201005193433-3ee972b1d078 // indirect
 	github.com/dolthub/fslock v0.0.3 // indirect
-	github.com/dolthub/vitess v0.0.0-20230310225942-1731d057dc71 // indirect
 	github.com/dustin/go-humanize v1.0.0 // indirect
 	github.com/fatih/color v1.13.0 // indirect
 	github.com/go-kit/kit v0.10.0 // indirect
 	github.com/go-logr/logr v1.2.3 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
-	github.com/go-sql-driver/mysql v1.6.0 // indirect
 	github.com/gocraft/dbr/v2 v2.7.2 // indirect
 	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
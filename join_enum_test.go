@@ -0,0 +1,324 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/transform"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// maxJoinOrders caps the number of left-deep orders timed per query.
+// Above this we time a random sample plus the analyzer's order.
+const maxJoinOrders = 24
+
+// joinEnumColumns is the number of columns in every t{i} table: (id, v, w).
+const joinEnumColumns = 3
+
+// BenchmarkJoinEnumeration times every left-deep order of an N-way join
+// and reports where the analyzer's order ranks. Table t{i} has sizes[i]
+// rows and a filter that keeps selectivities[i] percent of them. Every
+// table joins on id, so every order can lookup join the next table on
+// t{first}.id.
+func BenchmarkJoinEnumeration(b *testing.B) {
+	configs := []struct {
+		sizes         []int
+		selectivities []int
+	}{
+		{
+			sizes:         []int{1000, 100, 10000},
+			selectivities: []int{100, 50, 1},
+		},
+		{
+			sizes:         []int{1000, 1000, 1000, 1000},
+			selectivities: []int{100, 100, 10, 1},
+		},
+		{
+			sizes:         []int{100, 1000, 1000, 10000, 10000, 1000},
+			selectivities: []int{100, 50, 50, 10, 100, 1},
+		},
+		{
+			sizes:         []int{1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000},
+			selectivities: []int{100, 90, 80, 50, 50, 20, 10, 1},
+		},
+	}
+
+	for _, cfg := range configs {
+		n := len(cfg.sizes)
+		e, ctx := setupMemDB()
+
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		for t, size := range cfg.sizes {
+			s.WriteString(fmt.Sprintf("create table t%d (id int primary key, v int, w int);", t))
+			// a different multiplier per table decorrelates the filters
+			mult := []int{37, 41, 43, 47, 53, 59, 61, 67}[t]
+			insertRows(s, fmt.Sprintf("t%d", t), size, func(i int) string {
+				return fmt.Sprintf("%d, %d, %d", i, i*mult%100, i)
+			})
+		}
+		runSetup(e, ctx, s.String())
+
+		var from, where []string
+		for t := 0; t < n; t++ {
+			if t == 0 {
+				from = append(from, "t0")
+			} else {
+				from = append(from, fmt.Sprintf("join t%d on t%d.id = t%d.id", t, t-1, t))
+			}
+			where = append(where, fmt.Sprintf("t%d.v < %d", t, cfg.selectivities[t]))
+		}
+		q := fmt.Sprintf("select * from %s where %s", strings.Join(from, " "), strings.Join(where, " and "))
+		analyzed := logAnalyzedPlan(e, ctx, q)
+		analyzerOrder, leftDeep := leftDeepTableOrder(analyzed)
+
+		tables := make([]sql.Table, n)
+		pks := make([]sql.Index, n)
+		var db sql.Database
+		for t := range tables {
+			var err error
+			tables[t], db, err = e.Analyzer.Catalog.Table(ctx, "test", fmt.Sprintf("t%d", t))
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+			indexable, ok := tables[t].(sql.IndexAddressableTable)
+			if !ok {
+				log.Fatalf("t%d not index addressable", t)
+			}
+			indexes, err := indexable.GetIndexes(ctx)
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+			pks[t] = indexes[0]
+		}
+
+		orders := sampleJoinOrders(n, maxJoinOrders)
+		analyzerIdx := -1
+		for i, o := range orders {
+			if joinOrderString(o) == joinOrderString(analyzerOrder) {
+				analyzerIdx = i
+			}
+		}
+		if analyzerIdx < 0 && leftDeep && len(analyzerOrder) == n {
+			orders = append(orders, analyzerOrder)
+			analyzerIdx = len(orders) - 1
+		}
+
+		type timedOrder struct {
+			order []int
+			node  sql.Node
+			nsOp  int64
+		}
+		// orders that -bench filters out are not run, and are left out
+		// of the ranking
+		var first sql.Node
+		var timed []timedOrder
+		for i, o := range orders {
+			node := leftDeepJoinPlan(tables, pks, db, cfg.selectivities, o)
+			if i == 0 {
+				first = node
+			} else {
+				checkEquivalent(ctx, joinOrderString(o), first, node)
+			}
+			nsOp := runOneBench(b, ctx, fmt.Sprintf("%d table order %s", n, joinOrderString(o)), node)
+			if nsOp > 0 {
+				timed = append(timed, timedOrder{order: o, node: node, nsOp: nsOp})
+			}
+		}
+
+		checkEquivalent(ctx, "analyzed plan", first, analyzed)
+		analyzedNsOp := runOneBench(b, ctx, fmt.Sprintf("%d table analyzed plan", n), analyzed)
+
+		var analyzerTimed timedOrder
+		analyzerRan := false
+		for _, t := range timed {
+			if analyzerIdx >= 0 && joinOrderString(t.order) == joinOrderString(orders[analyzerIdx]) {
+				analyzerTimed, analyzerRan = t, true
+			}
+		}
+		sort.Slice(timed, func(i, j int) bool {
+			return timed[i].nsOp < timed[j].nsOp
+		})
+
+		report := &strings.Builder{}
+		report.WriteString(fmt.Sprintf("%d tables, %d of %d orders timed:\n", n, len(timed), factorial(n)))
+		for i, t := range timed {
+			marker := ""
+			if analyzerRan && joinOrderString(t.order) == joinOrderString(analyzerTimed.order) {
+				marker = " <- analyzer"
+			}
+			report.WriteString(fmt.Sprintf("  %2d. %-40s %12d ns/op%s\n", i+1, joinOrderString(t.order), t.nsOp, marker))
+		}
+		if len(timed) == 0 {
+			report.WriteString("no order was timed, so nothing is ranked\n")
+			log.Print(report.String())
+			continue
+		}
+		best := timed[0]
+		if !leftDeep {
+			report.WriteString("analyzed plan is not left-deep, so it has no table order\n")
+		} else if analyzerIdx < 0 {
+			report.WriteString(fmt.Sprintf("analyzer order %s does not cover every table\n", joinOrderString(analyzerOrder)))
+		} else if !analyzerRan {
+			report.WriteString(fmt.Sprintf("analyzer order %s was not timed\n", joinOrderString(analyzerOrder)))
+		} else {
+			report.WriteString(fmt.Sprintf("analyzer order is %.1fx the best order\n", float64(analyzerTimed.nsOp)/float64(best.nsOp)))
+		}
+		if analyzedNsOp > 0 {
+			report.WriteString(fmt.Sprintf("analyzed plan with its own operators is %.1fx the best order\n", float64(analyzedNsOp)/float64(best.nsOp)))
+		}
+		log.Print(report.String())
+
+		if analyzerRan {
+			name := fmt.Sprintf("%d table join order", n)
+			runBenchmarkComparison(b, ctx, name, analyzerTimed.node, best.node)
+		}
+	}
+}
+
+// leftDeepJoinPlan builds a left-deep chain of lookup joins over
+// |tables| in |order|. Every table after the first is a filtered point
+// lookup keyed by the first table's id, and the result is projected
+// back into table order so that every order returns identical rows.
+func leftDeepJoinPlan(tables []sql.Table, pks []sql.Index, db sql.Database, selectivities []int, order []int) sql.Node {
+	filter := func(t int) sql.Expression {
+		return expression.NewLessThan(
+			expression.NewGetField(1, types.Int64, "v", true),
+			expression.NewLiteral(selectivities[t], types.Int64),
+		)
+	}
+
+	first := order[0]
+	var node sql.Node = plan.NewFilter(filter(first), plan.NewResolvedTable(tables[first], db, nil))
+	for pos, t := range order[1:] {
+		offset := (pos + 1) * joinEnumColumns
+		node = plan.NewJoin(
+			node,
+			plan.NewFilter(
+				filter(t),
+				mustIndexedAccessForResolvedTable(
+					plan.NewResolvedTable(tables[t], db, nil),
+					plan.NewLookupBuilder(
+						pks[t],
+						[]sql.Expression{
+							expression.NewGetField(0, types.Int64, "id", false),
+						},
+						[]bool{false},
+					),
+				),
+			),
+			plan.JoinTypeLookup,
+			expression.NewEquals(
+				expression.NewGetField(0, types.Int64, "id", false),
+				expression.NewGetField(offset, types.Int64, "id", false),
+			),
+		)
+	}
+
+	positions := make([]int, len(order))
+	for pos, t := range order {
+		positions[t] = pos
+	}
+	var projections []sql.Expression
+	for t := range order {
+		sch := tables[t].Schema()
+		for c := 0; c < joinEnumColumns; c++ {
+			projections = append(projections, expression.NewGetField(positions[t]*joinEnumColumns+c, sch[c].Type, sch[c].Name, sch[c].Nullable))
+		}
+	}
+	return plan.NewProject(projections, node)
+}
+
+// leftDeepTableOrder returns the t{i} table indexes in the order they
+// appear as leaves of |n|. The leaves are only a join order if |n| is
+// left-deep, so it also reports false if any join has another join as
+// its right child.
+func leftDeepTableOrder(n sql.Node) ([]int, bool) {
+	var order []int
+	leftDeep := true
+	transform.Inspect(n, func(n sql.Node) bool {
+		var name string
+		switch n := n.(type) {
+		case *plan.JoinNode:
+			transform.Inspect(n.Right(), func(n sql.Node) bool {
+				if _, ok := n.(*plan.JoinNode); ok {
+					leftDeep = false
+				}
+				return leftDeep
+			})
+			return true
+		case *plan.IndexedTableAccess:
+			name = n.Name()
+		case *plan.ResolvedTable:
+			name = n.Name()
+		default:
+			return true
+		}
+		var t int
+		if _, err := fmt.Sscanf(name, "t%d", &t); err == nil {
+			order = append(order, t)
+		}
+		return false
+	})
+	return order, leftDeep
+}
+
+// sampleJoinOrders returns every permutation of [0, n) if there are at
+// most |limit|, otherwise |limit| distinct permutations chosen with a
+// fixed seed.
+func sampleJoinOrders(n, limit int) [][]int {
+	base := make([]int, n)
+	for i := range base {
+		base[i] = i
+	}
+	if factorial(n) <= limit {
+		var orders [][]int
+		var permute func(k int)
+		permute = func(k int) {
+			if k == n {
+				orders = append(orders, append([]int{}, base...))
+				return
+			}
+			for i := k; i < n; i++ {
+				base[k], base[i] = base[i], base[k]
+				permute(k + 1)
+				base[k], base[i] = base[i], base[k]
+			}
+		}
+		permute(0)
+		return orders
+	}
+
+	r := rand.New(rand.NewSource(0))
+	seen := make(map[string]bool)
+	var orders [][]int
+	for len(orders) < limit {
+		o := r.Perm(n)
+		if key := joinOrderString(o); !seen[key] {
+			seen[key] = true
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+func joinOrderString(order []int) string {
+	names := make([]string, len(order))
+	for i, t := range order {
+		names[i] = fmt.Sprintf("t%d", t)
+	}
+	return strings.Join(names, ",")
+}
+
+func factorial(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return n * factorial(n-1)
+}
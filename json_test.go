@@ -0,0 +1,188 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+// BenchmarkJSON compares a JSON_EXTRACT filter against an indexed int
+// column, and reading whole documents against extracted fields and
+// plain columns, for documents of different widths and depths.
+//
+// The pinned GMS version parses generated columns but does not compute
+// them, so the indexed alternative to JSON_EXTRACT is a plain column
+// |a| that the insert keeps equal to doc->'$.a'.
+func BenchmarkJSON(b *testing.B) {
+	for _, cfg := range []struct {
+		keys  int
+		depth int
+	}{
+		{keys: 4, depth: 1},
+		{keys: 64, depth: 1},
+		{keys: 16, depth: 4},
+	} {
+		e, ctx := setupMemDB()
+
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString("create table js (id int primary key, doc json, a int, key(a));")
+		insertRows(s, "js", 1000, func(i int) string {
+			return fmt.Sprintf("%d, '%s', %d", i, jsonDoc(i%100, cfg.keys, cfg.depth), i%100)
+		})
+		runSetup(e, ctx, s.String())
+
+		// the parser accepts generated columns, but the expression is
+		// dropped and the column is left NULL
+		for _, col := range []struct {
+			name string
+			kind string
+		}{
+			{name: "gs", kind: "stored"},
+			{name: "gv", kind: "virtual"},
+		} {
+			alter := fmt.Sprintf("alter table js add column %s int generated always as (json_extract(doc, '$.a')) %s", col.name, col.kind)
+			sch, iter, err := e.Query(ctx, alter)
+			if err == nil {
+				_, err = sql.RowIterToRows(ctx, sch, iter)
+			}
+			if err != nil {
+				log.Printf("'%s' is not supported: %s\n", alter, err)
+				continue
+			}
+			count := fmt.Sprintf("select count(%s) from js", col.name)
+			sch, iter, err = e.Query(ctx, count)
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+			rows, err := sql.RowIterToRows(ctx, sch, iter)
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+			log.Printf("'%s' succeeded, '%s' returns %v\n", alter, count, rows[0][0])
+			runSetup(e, ctx, fmt.Sprintf("alter table js drop column %s", col.name))
+		}
+
+		for _, q := range []string{
+			"select * from js where json_extract(doc, '$.a') = 5",
+			"select * from js where a = 5",
+			"select doc from js",
+			"select json_extract(doc, '$.a') from js",
+			"select a from js",
+		} {
+			logAnalyzedPlan(e, ctx, q)
+		}
+
+		js, db, err := e.Analyzer.Catalog.Table(ctx, "test", "js")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+
+		jsIndexable, ok := js.(sql.IndexAddressableTable)
+		if !ok {
+			log.Fatalf("js not index addressable")
+		}
+		jsIndexes, err := jsIndexable.GetIndexes(ctx)
+		aIdx := jsIndexes[1]
+
+		extractA := func(idx int) sql.Expression {
+			ret, err := function.NewJSONExtract(
+				expression.NewGetField(idx, types.JSON, "doc", true),
+				expression.NewLiteral("$.a", types.LongText),
+			)
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+			return ret
+		}
+		docOnly := plan.NewResolvedTable(js.(*sqle.AlterableDoltTable).WithProjections([]string{"doc"}), db, nil)
+
+		// filters return identical rows and are checked, projections
+		// return different values and are only timed
+		tests := []struct {
+			name  string
+			pre   sql.Node
+			post  sql.Node
+			check bool
+		}{
+			{
+				name: fmt.Sprintf("json_extract filter vs indexed column keys %d depth %d", cfg.keys, cfg.depth),
+				pre: plan.NewFilter(
+					expression.NewEquals(
+						extractA(1),
+						expression.NewLiteral(5, types.Int64),
+					),
+					plan.NewResolvedTable(js, db, nil),
+				),
+				post: mustStaticIndexedAccessForResolvedTable(
+					plan.NewResolvedTable(js, db, nil),
+					sql.IndexLookup{
+						Index: aIdx,
+						Ranges: sql.RangeCollection{
+							sql.Range{sql.ClosedRangeColumnExpr(5, 5, types.Int64)},
+						},
+					}),
+				check: true,
+			},
+			{
+				name: fmt.Sprintf("whole document vs extracted field keys %d depth %d", cfg.keys, cfg.depth),
+				pre: plan.NewProject(
+					[]sql.Expression{
+						expression.NewGetField(0, types.JSON, "doc", true),
+					},
+					docOnly,
+				),
+				post: plan.NewProject(
+					[]sql.Expression{
+						extractA(0),
+					},
+					docOnly,
+				),
+			},
+			{
+				name: fmt.Sprintf("extracted field vs plain column keys %d depth %d", cfg.keys, cfg.depth),
+				pre: plan.NewProject(
+					[]sql.Expression{
+						extractA(0),
+					},
+					docOnly,
+				),
+				post: plan.NewResolvedTable(js.(*sqle.AlterableDoltTable).WithProjections([]string{"a"}), db, nil),
+			},
+		}
+
+		for _, bb := range tests {
+			if bb.check {
+				checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+			}
+			runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+		}
+	}
+}
+
+// jsonDoc renders an object with a top-level "a": |a|, |keys| string
+// fields per level, and "n" nesting |depth|-1 more levels below it.
+func jsonDoc(a, keys, depth int) string {
+	var level func(d int) string
+	level = func(d int) string {
+		fields := make([]string, 0, keys+2)
+		if d == 0 {
+			fields = append(fields, fmt.Sprintf(`"a": %d`, a))
+		}
+		for k := 0; k < keys; k++ {
+			fields = append(fields, fmt.Sprintf(`"k%d": "value %08d"`, k, k))
+		}
+		if d < depth-1 {
+			fields = append(fields, fmt.Sprintf(`"n": %s`, level(d+1)))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return level(0)
+}
@@ -0,0 +1,172 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+// BenchmarkKeyless compares a keyless table against a primary key table
+// holding the same rows. Keyless rows are keyed by a hash of the whole
+// row plus a count of identical copies, so every secondary index entry
+// points at that hash.
+func BenchmarkKeyless(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	// pk and kl hold the same 1000 rows. dpk and dkl hold 100 distinct
+	// rows repeated 10 times each, dpk with a surrogate key.
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table pk (x int primary key, y int, z int, w int, key(y));")
+	s.WriteString("create table kl (x int, y int, z int, w int, key(x), key(y));")
+	s.WriteString("create table uv (u int primary key, v int, r int, s int);")
+	s.WriteString("create table dpk (id int primary key auto_increment, y int, w int, key(y));")
+	s.WriteString("create table dkl (y int, w int, key(y));")
+	for _, table := range []string{"pk", "kl"} {
+		insertRows(s, table, 1000, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d, %d", i, i%100, i, i)
+		})
+	}
+	insertRows(s, "uv", 1000, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+	})
+	insertRows(s, "dpk", 1000, func(i int) string {
+		return fmt.Sprintf("%d, %d, 0", i+1, i%100)
+	})
+	insertRows(s, "dkl", 1000, func(i int) string {
+		return fmt.Sprintf("%d, 0", i%100)
+	})
+	runSetup(e, ctx, s.String())
+
+	for _, q := range []string{
+		"select * from kl where y = 5",
+		"select * from uv join kl on u = x",
+		"select * from uv join pk on u = x",
+	} {
+		logAnalyzedPlan(e, ctx, q)
+	}
+
+	pk, db, err := e.Analyzer.Catalog.Table(ctx, "test", "pk")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	kl, db, err := e.Analyzer.Catalog.Table(ctx, "test", "kl")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	pkIndexable, ok := pk.(sql.IndexAddressableTable)
+	if !ok {
+		log.Fatalf("pk not index addressable")
+	}
+	pkIndexes, err := pkIndexable.GetIndexes(ctx)
+	pkPk := pkIndexes[0]
+	pkYIdx := pkIndexes[1]
+
+	klIndexable, ok := kl.(sql.IndexAddressableTable)
+	if !ok {
+		log.Fatalf("kl not index addressable")
+	}
+	klIndexes, err := klIndexable.GetIndexes(ctx)
+	klXIdx := klIndexes[0]
+	klYIdx := klIndexes[1]
+
+	yLookup := func(t sql.Table, idx sql.Index) sql.Node {
+		return mustStaticIndexedAccessForResolvedTable(
+			plan.NewResolvedTable(t, db, nil),
+			sql.IndexLookup{
+				Index: idx,
+				Ranges: sql.RangeCollection{
+					sql.Range{sql.ClosedRangeColumnExpr(5, 5, types.Int64)},
+				},
+			})
+	}
+	xJoin := func(t sql.Table, idx sql.Index) sql.Node {
+		return plan.NewJoin(
+			plan.NewResolvedTable(uv, db, nil),
+			mustIndexedAccessForResolvedTable(
+				plan.NewResolvedTable(t, db, nil),
+				plan.NewLookupBuilder(
+					idx,
+					[]sql.Expression{
+						expression.NewGetField(0, types.Int64, "u", false),
+					},
+					[]bool{false},
+				),
+			),
+			plan.JoinTypeLookup,
+			expression.NewEquals(
+				expression.NewGetField(0, types.Int64, "u", false),
+				expression.NewGetField(4, types.Int64, "x", true),
+			),
+		)
+	}
+
+	tests := []struct {
+		name string
+		pre  sql.Node
+		post sql.Node
+	}{
+		{
+			name: "table scan pk vs keyless",
+			pre:  plan.NewResolvedTable(pk, db, nil),
+			post: plan.NewResolvedTable(kl, db, nil),
+		},
+		{
+			name: "secondary index lookup pk vs keyless",
+			pre:  yLookup(pk, pkYIdx),
+			post: yLookup(kl, klYIdx),
+		},
+		{
+			name: "lookup join pk vs keyless",
+			pre:  xJoin(pk, pkPk),
+			post: xJoin(kl, klXIdx),
+		},
+	}
+
+	for _, bb := range tests {
+		checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+	}
+
+	// writes go through the engine; each insert is undone untimed so
+	// the tables do not grow
+	insert := func(table string) string {
+		s := &strings.Builder{}
+		insertRows(s, table, 100, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d, %d", 100000+i, i, i, i)
+		})
+		return strings.TrimSuffix(s.String(), ";\n")
+	}
+	runQueryComparison(b, e, ctx, "insert 100 rows pk vs keyless",
+		insert("pk"),
+		insert("kl"),
+		"delete from pk where x >= 100000; delete from kl where x >= 100000",
+	)
+
+	// y = 5 matches 10 rows in both tables, 10 separate rows in dpk and
+	// one row with a count of 10 in dkl
+	for _, t := range []string{"dpk", "dkl"} {
+		log.Printf("'select count(*) from %s where y = 5' returns %v\n", t, runQuery(e, ctx, fmt.Sprintf("select count(*) from %s where y = 5", t))[0][0])
+	}
+	runQueryComparison(b, e, ctx, "update duplicated rows pk vs keyless",
+		"update dpk set w = w + 1 where y = 5",
+		"update dkl set w = w + 1 where y = 5",
+		"",
+	)
+	for _, t := range []string{"dpk", "dkl"} {
+		q := fmt.Sprintf("select w, count(*) from %s where y = 5 group by w", t)
+		log.Printf("'%s' returns %v\n", q, runQuery(e, ctx, q))
+	}
+}
@@ -0,0 +1,155 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+func BenchmarkLeftJoinOp(b *testing.B) {
+	// percentage of xy rows without a uv match, which are null-extended
+	for _, unmatched := range []int{0, 50, 90} {
+		e, ctx := setupMemDB()
+
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString("create table xy (x int primary key, y int, z int, w int);")
+		s.WriteString("create table uv (u int primary key, v int, r int, s int);")
+		insertRows(s, "xy", 101, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+		})
+		insertRows(s, "uv", 101, func(i int) string {
+			u := i
+			if i%100 < unmatched {
+				u += 1000
+			}
+			return fmt.Sprintf("%d, %d, %d, %d", u, i, i, i)
+		})
+		runSetup(e, ctx, s.String())
+
+		xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+
+		uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+
+		uvIndexable, ok := uv.(sql.IndexAddressableTable)
+		if !ok {
+			log.Fatalf("uv not index addressable")
+		}
+		uvIndexes, err := uvIndexable.GetIndexes(ctx)
+		uvPk := uvIndexes[0]
+
+		xyIndexable, ok := xy.(sql.IndexAddressableTable)
+		if !ok {
+			log.Fatalf("xy not index addressable")
+		}
+		xyIndexes, err := xyIndexable.GetIndexes(ctx)
+		xyPk := xyIndexes[0]
+
+		logAnalyzedPlan(e, ctx, "select * from xy left join uv on x = u")
+
+		nestedLoop := plan.NewJoin(
+			plan.NewResolvedTable(xy, db, nil),
+			plan.NewResolvedTable(uv, db, nil),
+			plan.JoinTypeLeftOuter,
+			expression.NewEquals(
+				expression.NewGetField(0, types.Int64, "x", false),
+				expression.NewGetField(4, types.Int64, "u", false),
+			),
+		)
+		lookup := plan.NewJoin(
+			plan.NewResolvedTable(xy, db, nil),
+			mustIndexedAccessForResolvedTable(
+				plan.NewResolvedTable(uv, db, nil),
+				plan.NewLookupBuilder(
+					uvPk,
+					[]sql.Expression{
+						expression.NewGetField(0, types.Int64, "x", false),
+					},
+					[]bool{false},
+				),
+			),
+			plan.JoinTypeLeftOuterLookup,
+			expression.NewEquals(
+				expression.NewGetField(0, types.Int64, "x", false),
+				expression.NewGetField(4, types.Int64, "u", false),
+			),
+		)
+		hash := plan.NewJoin(
+			plan.NewResolvedTable(xy, db, nil),
+			plan.NewHashLookup(
+				plan.NewCachedResults(plan.NewResolvedTable(uv, db, nil)),
+				expression.NewGetField(0, types.Int64, "u", false),
+				expression.NewGetField(0, types.Int64, "x", false),
+			),
+			plan.JoinTypeLeftOuterHash,
+			expression.NewEquals(
+				expression.NewGetField(0, types.Int64, "x", false),
+				expression.NewGetField(4, types.Int64, "u", false),
+			),
+		)
+		merge := plan.NewJoin(
+			mustStaticIndexedAccessForResolvedTable(
+				plan.NewResolvedTable(xy, db, nil),
+				sql.IndexLookup{
+					Index: xyPk,
+					Ranges: sql.RangeCollection{
+						sql.Range{sql.AllRangeColumnExpr(types.Int64)},
+					},
+				},
+			),
+			mustStaticIndexedAccessForResolvedTable(
+				plan.NewResolvedTable(uv, db, nil),
+				sql.IndexLookup{
+					Index: uvPk,
+					Ranges: sql.RangeCollection{
+						sql.Range{sql.AllRangeColumnExpr(types.Int64)},
+					},
+				},
+			),
+			plan.JoinTypeLeftOuterMerge,
+			expression.NewEquals(
+				expression.NewGetField(0, types.Int64, "x", false),
+				expression.NewGetField(4, types.Int64, "u", false),
+			),
+		)
+
+		tests := []struct {
+			name string
+			pre  sql.Node
+			post sql.Node
+		}{
+			{
+				name: "left join vs left lookup join",
+				pre:  nestedLoop,
+				post: lookup,
+			},
+			{
+				name: "left lookup vs left hash join",
+				pre:  lookup,
+				post: hash,
+			},
+			{
+				name: "left lookup vs left merge join",
+				pre:  lookup,
+				post: merge,
+			},
+		}
+
+		for _, bb := range tests {
+			name := fmt.Sprintf("%s %d%% unmatched", bb.name, unmatched)
+			checkEquivalent(ctx, name, bb.pre, bb.post)
+			runBenchmarkComparison(b, ctx, name, bb.pre, bb.post)
+		}
+	}
+}
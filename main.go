@@ -5,16 +5,23 @@ import (
 	"fmt"
 	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
 	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	sqle2 "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
 	"github.com/dolthub/dolt/go/store/types"
 	sqle "github.com/dolthub/go-mysql-server"
 	"github.com/dolthub/go-mysql-server/enginetest"
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/transform"
 	"log"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupMemDB() (*sqle.Engine, *sql.Context) {
@@ -55,21 +62,227 @@ func runBenchmarkComparison(b *testing.B, ctx *sql.Context, name string, pre, po
 	runOneBench(b, ctx, fmt.Sprintf("%s post-opt", name), post)
 }
 
-func runOneBench(b *testing.B, ctx *sql.Context, name string, node sql.Node) {
-	var r []sql.Row
+// runOneBench runs |node| as a sub-benchmark and returns its ns/op.
+func runOneBench(b *testing.B, ctx *sql.Context, name string, node sql.Node) int64 {
+	sch := node.Schema()
+	return runTimedBench(b, name, func(int) {
+		iter, err := node.RowIter(ctx, nil)
+		if err != nil {
+			log.Fatalf("iter query error '%s': %s\n", sql.DebugString(node), err)
+		}
+		res, err = sql.RowIterToRows(ctx, sch, iter)
+		if err != nil {
+			log.Fatalf("setup executing query '%s': %s\n", sql.DebugString(node), err)
+		}
+	}, nil)
+}
+
+// runTimedBench runs |run| b.N times as a sub-benchmark, passing the
+// iteration number, and returns its ns/op, or 0 if -bench filters it
+// out. |reset| runs untimed after each iteration, and is skipped if
+// nil. testing.Benchmark would be simpler, but it deadlocks when called
+// from inside a running benchmark.
+func runTimedBench(b *testing.B, name string, run func(n int), reset func()) int64 {
+	var nsOp int64
 	b.Run(name, func(b *testing.B) {
-		sch := node.Schema()
-		b.ResetTimer()
+		var elapsed time.Duration
 		for n := 0; n < b.N; n++ {
-			iter, err := node.RowIter(ctx, nil)
-			if err != nil {
-				log.Fatalf("iter query error '%s': %s\n", sql.DebugString(n), err)
-			}
-			r, err = sql.RowIterToRows(ctx, sch, iter)
-			if err != nil {
-				log.Fatalf("setup executing query '%s': %s\n", sql.DebugString(node), err)
+			start := time.Now()
+			run(n)
+			elapsed += time.Since(start)
+			if reset != nil {
+				b.StopTimer()
+				reset()
+				b.StartTimer()
 			}
 		}
+		nsOp = elapsed.Nanoseconds() / int64(b.N)
+	})
+	return nsOp
+}
+
+// runQueryComparison times |pre| and |post| as SQL statements through
+// the full engine. |reset| is a ;-separated list of statements that runs
+// untimed after each execution to undo writes, and is skipped if empty.
+func runQueryComparison(b *testing.B, e *sqle.Engine, ctx *sql.Context, name, pre, post, reset string) {
+	log.Printf("pre: %s\n", pre)
+	log.Printf("post: %s\n", post)
+	runOneQueryBench(b, e, ctx, fmt.Sprintf("%s pre-opt", name), pre, reset)
+	runOneQueryBench(b, e, ctx, fmt.Sprintf("%s post-opt", name), post, reset)
+}
+
+// runOneQueryBench times |q| through the full engine and returns its
+// ns/op, excluding the untimed |reset|.
+func runOneQueryBench(b *testing.B, e *sqle.Engine, ctx *sql.Context, name, q, reset string) int64 {
+	var resetFn func()
+	if reset != "" {
+		resetFn = func() {
+			runSetup(e, ctx, reset)
+		}
+	}
+	return runTimedBench(b, name, func(int) {
+		runQuery(e, ctx, q)
+	}, resetFn)
+}
+
+func runQuery(e *sqle.Engine, ctx *sql.Context, q string) []sql.Row {
+	sch, iter, err := e.Query(ctx, q)
+	if err != nil {
+		log.Fatalf("analyzing query '%s': %s\n", q, err)
+	}
+	rows, err := sql.RowIterToRows(ctx, sch, iter)
+	if err != nil {
+		log.Fatalf("executing query '%s': %s\n", q, err)
+	}
+	return rows
+}
+
+// insertRows appends an insert of |n| rows into |table| to |s|, using
+// |values| to render the tuple body of row |i|.
+func insertRows(s *strings.Builder, table string, n int, values func(i int) string) {
+	s.WriteString(fmt.Sprintf("insert into %s values\n  ", table))
+	for i := 0; i < n; i++ {
+		s.WriteString(fmt.Sprintf("  (%s)", values(i)))
+		if i == n-1 {
+			s.WriteString(";\n")
+		} else {
+			s.WriteString(",\n")
+		}
+	}
+}
+
+func runSetup(e *sqle.Engine, ctx *sql.Context, setup string) {
+	for _, q := range strings.Split(setup, ";") {
+		sch, iter, err := e.Query(ctx, q)
+		if err != nil {
+			log.Fatalf("setup analyzing query '%s': %s\n", q, err)
+		}
+		_, err = sql.RowIterToRows(ctx, sch, iter)
+		if err != nil {
+			log.Fatalf("setup executing query '%s': %s\n", q, err)
+		}
+	}
+}
+
+// logAnalyzedPlan prints the plan the analyzer chooses for |q|, which
+// is useful for checking whether a hand-written optimization is
+// applied automatically.
+func logAnalyzedPlan(e *sqle.Engine, ctx *sql.Context, q string) sql.Node {
+	n, err := e.AnalyzeQuery(ctx, q)
+	if err != nil {
+		log.Fatalf("analyzing query '%s': %s\n", q, err)
+	}
+	log.Printf("analyzed '%s':\n%s\n", q, sql.DebugString(n))
+	return n
+}
+
+// checkEquivalent executes |pre| and |post| once and fails if they
+// return different multisets of rows.
+func checkEquivalent(ctx *sql.Context, name string, pre, post sql.Node) {
+	checkSameRows(name, sortedRowStrings(ctx, pre), sortedRowStrings(ctx, post))
+}
+
+// checkTypedEquivalent is checkEquivalent for rows compared with their
+// Go types, so a value converted to another type is a mismatch.
+func checkTypedEquivalent(ctx *sql.Context, name string, pre, post sql.Node) {
+	checkSameRows(name, typedRowStrings(ctx, pre), typedRowStrings(ctx, post))
+}
+
+// checkSameRows fails if the rendered rows |pre| and |post| of |name|
+// are different.
+func checkSameRows(name string, pre, post []string) {
+	if len(pre) != len(post) {
+		log.Fatalf("%s: pre returned %d rows, post returned %d\n", name, len(pre), len(post))
+	}
+	for i := range pre {
+		if pre[i] != post[i] {
+			log.Fatalf("%s: result mismatch, pre row %s != post row %s\n", name, pre[i], post[i])
+		}
+	}
+}
+
+func sortedRowStrings(ctx *sql.Context, node sql.Node) []string {
+	return formatSortedRows(ctx, node, func(r sql.Row) string {
+		return fmt.Sprint(r)
+	})
+}
+
+// typedRowStrings is sortedRowStrings with the Go type of every value.
+func typedRowStrings(ctx *sql.Context, node sql.Node) []string {
+	return formatSortedRows(ctx, node, func(r sql.Row) string {
+		vals := make([]string, len(r))
+		for i, v := range r {
+			vals[i] = fmt.Sprintf("%T(%v)", v, v)
+		}
+		return strings.Join(vals, " ")
+	})
+}
+
+// formatSortedRows executes |node| and returns its rows rendered with
+// |format|, sorted.
+func formatSortedRows(ctx *sql.Context, node sql.Node, format func(sql.Row) string) []string {
+	iter, err := node.RowIter(ctx, nil)
+	if err != nil {
+		log.Fatalf("iter query error '%s': %s\n", sql.DebugString(node), err)
+	}
+	rows, err := sql.RowIterToRows(ctx, node.Schema(), iter)
+	if err != nil {
+		log.Fatalf("executing query '%s': %s\n", sql.DebugString(node), err)
+	}
+	ret := make([]string, len(rows))
+	for i, r := range rows {
+		ret[i] = format(r)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// firstRow returns the first row |n| produces.
+func firstRow(ctx *sql.Context, n sql.Node) sql.Row {
+	iter, err := n.RowIter(ctx, nil)
+	if err != nil {
+		log.Fatalf("iter query error '%s': %s\n", sql.DebugString(n), err)
+	}
+	defer iter.Close(ctx)
+	row, err := iter.Next(ctx)
+	if err != nil {
+		log.Fatalf("executing query '%s': %s\n", sql.DebugString(n), err)
+	}
+	return row
+}
+
+// hasIndexedAccess returns whether any table in |n| is read through an
+// index.
+func hasIndexedAccess(n sql.Node) bool {
+	found := false
+	transform.Inspect(n, func(n sql.Node) bool {
+		if _, ok := n.(*plan.IndexedTableAccess); ok {
+			found = true
+		}
+		return !found
 	})
-	res = r
+	return found
+}
+
+// tableStorage returns the number of prolly tree nodes and the total
+// bytes of the primary row data of |t|.
+func tableStorage(ctx *sql.Context, t sql.Table) (int, int) {
+	dt, err := t.(*sqle2.AlterableDoltTable).DoltTable.DoltTable(ctx)
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+	rows, err := dt.GetRowData(ctx)
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+	var nodes, bytes int
+	err = durable.ProllyMapFromIndex(rows).WalkNodes(ctx, func(ctx context.Context, nd tree.Node) error {
+		nodes++
+		bytes += nd.Size()
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+	return nodes, bytes
 }
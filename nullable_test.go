@@ -0,0 +1,185 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+// BenchmarkNullable measures IS NULL and IS NOT NULL index reads, scans
+// of nullable versus NOT NULL columns, and joins on nullable keys with
+// = and <=>, for several fractions of NULL keys.
+func BenchmarkNullable(b *testing.B) {
+	for _, nullPct := range []int{0, 10, 50} {
+		e, ctx := setupMemDB()
+
+		// xy.y and uv.v are NULL for nullPct percent of rows, at scattered
+		// positions so the NULL rows of each table differ. nn stores the
+		// same values as xy with -1 in place of NULL.
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString("create table xy (x int primary key, y int, z int, key(y));")
+		s.WriteString("create table nn (x int primary key, y int not null, z int, key(y));")
+		s.WriteString("create table uv (u int primary key, v int, r int, key(v));")
+		insertRows(s, "xy", 1000, func(i int) string {
+			if i%100 < nullPct {
+				return fmt.Sprintf("%d, NULL, %d", i, i)
+			}
+			return fmt.Sprintf("%d, %d, %d", i, i, i)
+		})
+		insertRows(s, "nn", 1000, func(i int) string {
+			if i%100 < nullPct {
+				return fmt.Sprintf("%d, -1, %d", i, i)
+			}
+			return fmt.Sprintf("%d, %d, %d", i, i, i)
+		})
+		insertRows(s, "uv", 1000, func(i int) string {
+			if (i*7)%100 < nullPct {
+				return fmt.Sprintf("%d, NULL, %d", i, i)
+			}
+			return fmt.Sprintf("%d, %d, %d", i, i, i)
+		})
+		runSetup(e, ctx, s.String())
+
+		for _, q := range []string{
+			"select * from xy where y is null",
+			"select * from xy where y is not null",
+			"select * from xy join uv on y = v",
+			"select * from xy join uv on y <=> v",
+		} {
+			log.Printf("%d%% null: '%s' returns %d rows\n", nullPct, q, len(sortedRowStrings(ctx, logAnalyzedPlan(e, ctx, q))))
+		}
+
+		xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+
+		nn, db, err := e.Analyzer.Catalog.Table(ctx, "test", "nn")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+
+		uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+
+		xyIndexable, ok := xy.(sql.IndexAddressableTable)
+		if !ok {
+			log.Fatalf("xy not index addressable")
+		}
+		xyIndexes, err := xyIndexable.GetIndexes(ctx)
+		yIdx := xyIndexes[1]
+
+		uvIndexable, ok := uv.(sql.IndexAddressableTable)
+		if !ok {
+			log.Fatalf("uv not index addressable")
+		}
+		uvIndexes, err := uvIndexable.GetIndexes(ctx)
+		vIdx := uvIndexes[1]
+
+		y := expression.NewGetField(1, types.Int64, "y", true)
+		yRead := func(rce sql.RangeColumnExpr) sql.Node {
+			return mustStaticIndexedAccessForResolvedTable(
+				plan.NewResolvedTable(xy, db, nil),
+				sql.IndexLookup{
+					Index:  yIdx,
+					Ranges: sql.RangeCollection{sql.Range{rce}},
+				})
+		}
+
+		// join returns xy joined to uv on y = v, or on y <=> v if
+		// |nullSafe|, with the given join type. Merge joins read both
+		// sides in key order through the secondary indexes and lookup
+		// joins probe uv.v.
+		join := func(joinType plan.JoinType, nullSafe bool) sql.Node {
+			var cond sql.Expression
+			if nullSafe {
+				cond = expression.NewNullSafeEquals(y, expression.NewGetField(4, types.Int64, "v", true))
+			} else {
+				cond = expression.NewEquals(y, expression.NewGetField(4, types.Int64, "v", true))
+			}
+			var left, right sql.Node = plan.NewResolvedTable(xy, db, nil), plan.NewResolvedTable(uv, db, nil)
+			switch joinType {
+			case plan.JoinTypeMerge:
+				left = yRead(sql.AllRangeColumnExpr(types.Int64))
+				right = mustStaticIndexedAccessForResolvedTable(
+					plan.NewResolvedTable(uv, db, nil),
+					sql.IndexLookup{
+						Index:  vIdx,
+						Ranges: sql.RangeCollection{sql.Range{sql.AllRangeColumnExpr(types.Int64)}},
+					})
+			case plan.JoinTypeLookup:
+				right = mustIndexedAccessForResolvedTable(
+					plan.NewResolvedTable(uv, db, nil),
+					plan.NewLookupBuilder(
+						vIdx,
+						[]sql.Expression{
+							expression.NewGetField(1, types.Int64, "y", true),
+						},
+						[]bool{nullSafe},
+					),
+				)
+			}
+			return plan.NewJoin(left, right, joinType, cond)
+		}
+
+		tests := []struct {
+			name  string
+			pre   sql.Node
+			post  sql.Node
+			check bool
+		}{
+			{
+				name:  fmt.Sprintf("is null scan vs index %d%% null", nullPct),
+				pre:   plan.NewFilter(expression.NewIsNull(y), plan.NewResolvedTable(xy, db, nil)),
+				post:  yRead(sql.NullRangeColumnExpr(types.Int64)),
+				check: true,
+			},
+			{
+				name:  fmt.Sprintf("is not null scan vs index %d%% null", nullPct),
+				pre:   plan.NewFilter(expression.NewNot(expression.NewIsNull(y)), plan.NewResolvedTable(xy, db, nil)),
+				post:  yRead(sql.NotNullRangeColumnExpr(types.Int64)),
+				check: true,
+			},
+			{
+				// different values, only timed
+				name: fmt.Sprintf("nullable vs not null scan %d%% null", nullPct),
+				pre:  plan.NewResolvedTable(xy, db, nil),
+				post: plan.NewResolvedTable(nn, db, nil),
+			},
+			{
+				name:  fmt.Sprintf("equals lookup join vs merge join %d%% null", nullPct),
+				pre:   join(plan.JoinTypeLookup, false),
+				post:  join(plan.JoinTypeMerge, false),
+				check: true,
+			},
+			{
+				name:  fmt.Sprintf("null safe lookup join vs merge join %d%% null", nullPct),
+				pre:   join(plan.JoinTypeLookup, true),
+				post:  join(plan.JoinTypeMerge, true),
+				check: true,
+			},
+		}
+
+		// a nested loop evaluates the join condition on every pair of
+		// rows, so it is the reference for the join operators
+		for _, nullSafe := range []bool{false, true} {
+			name := fmt.Sprintf("null safe %t nested loop vs lookup join %d%% null", nullSafe, nullPct)
+			checkEquivalent(ctx, name, join(plan.JoinTypeInner, nullSafe), join(plan.JoinTypeLookup, nullSafe))
+		}
+
+		for _, bb := range tests {
+			if bb.check {
+				checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+			}
+			runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/transform"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"log"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// phaseRules is the number of analyzer rules BenchmarkQueryPhases lists
+// for each query, slowest first.
+const phaseRules = 8
+
+// queryPhases is the time one query spends in each phase of a statement,
+// in ns/op. |build| is the conversion of the vitess AST into a plan.
+type queryPhases struct {
+	vitess, build, analyze, execute int64
+}
+
+func (p queryPhases) total() int64 {
+	return p.vitess + p.build + p.analyze + p.execute
+}
+
+// BenchmarkQueryPhases splits the SQL forms of the FAQ queries into
+// vitess parsing, plan building, analysis and execution, and lists the
+// analyzer rules that take the most time for each. Benchmarks that run a
+// pre-built plan only measure the execute column.
+func BenchmarkQueryPhases(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int, z int, key(y));")
+	s.WriteString("create table uv (u int primary key, v int, w int, key(v));")
+	for _, table := range []string{"xy", "uv"} {
+		insertRows(s, table, 10000, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d", i, i%100, i)
+		})
+	}
+	for k := 1; k <= 6; k++ {
+		s.WriteString(fmt.Sprintf("create table t%d (id int primary key, x int, key(x));", k))
+		insertRows(s, fmt.Sprintf("t%d", k), 1000, func(i int) string {
+			return fmt.Sprintf("%d, %d", i, (i*7+k)%1000)
+		})
+	}
+	runSetup(e, ctx, s.String())
+
+	inList := func(n int) string {
+		vals := make([]string, n)
+		for i := range vals {
+			vals[i] = fmt.Sprintf("%d", i*3)
+		}
+		return fmt.Sprintf("select * from xy where x in (%s)", strings.Join(vals, ", "))
+	}
+	join := func(n int) string {
+		q := &strings.Builder{}
+		q.WriteString("select count(*) from t1")
+		for k := 2; k <= n; k++ {
+			q.WriteString(fmt.Sprintf(" join t%d on t%d.x = t%d.id", k, k-1, k))
+		}
+		q.WriteString(" where t1.id < 10")
+		return q.String()
+	}
+
+	queries := []struct {
+		name  string
+		query string
+	}{
+		{name: "point lookup", query: "select * from xy where x = 500"},
+		{name: "index range", query: "select * from xy where y between 10 and 12"},
+		{name: "in list 10", query: inList(10)},
+		{name: "in list 1000", query: inList(1000)},
+		{name: "in list 10000", query: inList(10000)},
+		{name: "join 2 tables", query: join(2)},
+		{name: "join 4 tables", query: join(4)},
+		{name: "join 6 tables", query: join(6)},
+		{name: "semi join", query: "select * from xy where x in (select u from uv where v = 5)"},
+		{name: "scalar subquery", query: "select x, (select max(w) from uv where u = xy.x) from xy where x < 100"},
+		{name: "derived table depth 3", query: "select * from (select * from (select * from (select * from xy) a) b) c where x = 500"},
+		{name: "group by", query: "select y, count(*) from xy group by y"},
+	}
+
+	rules := timeAnalyzerRules(e)
+	report := &strings.Builder{}
+	report.WriteString(fmt.Sprintf("%-22s %12s %12s %12s %12s %12s %9s\n", "query", "vitess", "build", "analyze", "execute", "total", "analyze %"))
+	breakdown := &strings.Builder{}
+	for _, q := range queries {
+		n := logAnalyzedPlan(e, ctx, q.query)
+		log.Printf("%s returns %d rows\n", q.name, len(sortedRowStrings(ctx, n)))
+
+		p := runPhaseBench(b, e, ctx, q.name, q.query, n)
+		report.WriteString(fmt.Sprintf("%-22s %12d %12d %12d %12d %12d %8.1f%%\n", q.name, p.vitess, p.build, p.analyze, p.execute, p.total(), 100*float64(p.analyze)/float64(p.total())))
+
+		// a separate pass, so the rule timers do not slow the analyze
+		// column
+		perRule := rules.run(ctx, e, q.query, 100)
+		var rulesNs int64
+		for _, r := range perRule {
+			rulesNs += r.ns
+		}
+		breakdown.WriteString(fmt.Sprintf("%s: %d rules take %d ns with timers\n", q.name, len(perRule), rulesNs))
+		for i, r := range perRule {
+			if i == phaseRules {
+				break
+			}
+			breakdown.WriteString(fmt.Sprintf("  %-36s %12d %7.1f%%\n", r.id, r.ns, 100*float64(r.ns)/float64(rulesNs)))
+		}
+	}
+	log.Print(report.String())
+	log.Print(breakdown.String())
+}
+
+// runPhaseBench times each phase of |q| separately. |n| is the analyzed
+// plan of |q|, which the execute phase runs without analyzing it again.
+func runPhaseBench(b *testing.B, e *sqle.Engine, ctx *sql.Context, name, q string, n sql.Node) queryPhases {
+	var p queryPhases
+	p.vitess = runTimedBench(b, fmt.Sprintf("%s vitess", name), func(int) {
+		if _, err := sqlparser.Parse(q); err != nil {
+			log.Fatalf("parsing query '%s': %s\n", q, err)
+		}
+	}, nil)
+	parseNs := runTimedBench(b, fmt.Sprintf("%s parse", name), func(int) {
+		if _, err := parse.Parse(ctx, q); err != nil {
+			log.Fatalf("parsing query '%s': %s\n", q, err)
+		}
+	}, nil)
+	// parse.Parse runs the vitess parser before building the plan
+	p.build = parseNs - p.vitess
+	if p.build < 0 {
+		p.build = 0
+	}
+
+	parsed, err := parse.Parse(ctx, q)
+	if err != nil {
+		log.Fatalf("parsing query '%s': %s\n", q, err)
+	}
+	p.analyze = runTimedBench(b, fmt.Sprintf("%s analyze", name), func(int) {
+		if _, err := e.Analyzer.Analyze(ctx, parsed, nil); err != nil {
+			log.Fatalf("analyzing query '%s': %s\n", q, err)
+		}
+	}, nil)
+	p.execute = runOneBench(b, ctx, fmt.Sprintf("%s execute", name), n)
+	return p
+}
+
+// ruleTimer accumulates the time the analyzer spends in each rule while
+// it is enabled. Rules that analyze subqueries call the analyzer again,
+// so each rule is charged only for its own time, not for the rules it
+// runs.
+type ruleTimer struct {
+	enabled bool
+	total   map[analyzer.RuleId]time.Duration
+	// nested holds the time of rules running inside each rule that is
+	// in progress, innermost last
+	nested []time.Duration
+}
+
+// ruleTime is the time one analyzer rule took per analysis.
+type ruleTime struct {
+	id analyzer.RuleId
+	ns int64
+}
+
+// timeAnalyzerRules wraps every rule of |e|'s analyzer with a timer,
+// which does nothing until ruleTimer.run enables it. The analyzer's
+// batches share their rule slices with the package defaults, so each
+// batch gets a copy.
+func timeAnalyzerRules(e *sqle.Engine) *ruleTimer {
+	t := &ruleTimer{total: make(map[analyzer.RuleId]time.Duration)}
+	for _, batch := range e.Analyzer.Batches {
+		rules := make([]analyzer.Rule, len(batch.Rules))
+		for i, rule := range batch.Rules {
+			id, apply := rule.Id, rule.Apply
+			rules[i] = analyzer.Rule{
+				Id: id,
+				Apply: func(ctx *sql.Context, a *analyzer.Analyzer, n sql.Node, scope *analyzer.Scope, sel analyzer.RuleSelector) (sql.Node, transform.TreeIdentity, error) {
+					if !t.enabled {
+						return apply(ctx, a, n, scope, sel)
+					}
+					t.nested = append(t.nested, 0)
+					start := time.Now()
+					n, same, err := apply(ctx, a, n, scope, sel)
+					elapsed := time.Since(start)
+					last := len(t.nested) - 1
+					t.total[id] += elapsed - t.nested[last]
+					t.nested = t.nested[:last]
+					if last > 0 {
+						t.nested[last-1] += elapsed
+					}
+					return n, same, err
+				},
+			}
+		}
+		batch.Rules = rules
+	}
+	return t
+}
+
+// run parses and analyzes |q| |iters| times with the timers enabled, and
+// returns the time per analysis of every rule, slowest first.
+func (t *ruleTimer) run(ctx *sql.Context, e *sqle.Engine, q string, iters int) []ruleTime {
+	parsed, err := parse.Parse(ctx, q)
+	if err != nil {
+		log.Fatalf("parsing query '%s': %s\n", q, err)
+	}
+	t.total = make(map[analyzer.RuleId]time.Duration)
+	t.enabled = true
+	for i := 0; i < iters; i++ {
+		if _, err := e.Analyzer.Analyze(ctx, parsed, nil); err != nil {
+			log.Fatalf("analyzing query '%s': %s\n", q, err)
+		}
+	}
+	t.enabled = false
+
+	ret := make([]ruleTime, 0, len(t.total))
+	for id, d := range t.total {
+		ret = append(ret, ruleTime{id: id, ns: d.Nanoseconds() / int64(iters)})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ns > ret[j].ns
+	})
+	return ret
+}
@@ -0,0 +1,223 @@
+package query_faq_toy
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// pkTypeRows is the number of rows in every primary key fixture, and
+// pkTypeBatch is the number of rows per insert statement.
+const (
+	pkTypeRows  = 5000
+	pkTypeBatch = 100
+)
+
+// pkType is one primary key layout for BenchmarkPrimaryKeyType. Row i
+// has the primary key key(i), rendered in SQL by literal(i) and ordered
+// in storage by sortKey(i). Rows are inserted in |order|. A |generated|
+// key is left out of the inserts, so the table assigns it.
+type pkType struct {
+	name      string
+	columns   string
+	key       func(i int) []interface{}
+	literal   func(i int) string
+	sortKey   func(i int) string
+	order     []int
+	generated bool
+}
+
+// BenchmarkPrimaryKeyType compares primary key types and insertion
+// orders on bulk inserts, point lookups, range scans and storage size.
+// Each key type is inserted in key order and in random order, with the
+// same set of keys, so order and key width can be compared separately.
+func BenchmarkPrimaryKeyType(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	ascending := make([]int, pkTypeRows)
+	for i := range ascending {
+		ascending[i] = i
+	}
+	shuffled := r.Perm(pkTypeRows)
+
+	// random UUIDs are generated once so both orders share them
+	uuids := make([][]byte, pkTypeRows)
+	for i := range uuids {
+		uuids[i] = make([]byte, 16)
+		r.Read(uuids[i])
+	}
+	seqUUID := func(i int) []byte {
+		ret := make([]byte, 16)
+		ret[12], ret[13], ret[14], ret[15] = byte(i>>24), byte(i>>16), byte(i>>8), byte(i)
+		return ret
+	}
+
+	// int keys start at 1, like the keys auto_increment generates
+	intKey := func(columns string, order []int) pkType {
+		return pkType{
+			columns: columns,
+			key:     func(i int) []interface{} { return []interface{}{i + 1} },
+			literal: func(i int) string { return fmt.Sprintf("%d", i+1) },
+			sortKey: func(i int) string { return fmt.Sprintf("%010d", i+1) },
+			order:   order,
+		}
+	}
+	binaryKey := func(uuid func(i int) []byte, order []int) pkType {
+		return pkType{
+			columns: "id binary(16) primary key",
+			key:     func(i int) []interface{} { return []interface{}{string(uuid(i))} },
+			literal: func(i int) string { return fmt.Sprintf("X'%s'", hex.EncodeToString(uuid(i))) },
+			sortKey: func(i int) string { return string(uuid(i)) },
+			order:   order,
+		}
+	}
+	varcharKey := func(order []int) pkType {
+		return pkType{
+			columns: "id varchar(40) primary key",
+			key:     func(i int) []interface{} { return []interface{}{fmt.Sprintf("customer-%08d@example.com", i)} },
+			literal: func(i int) string { return fmt.Sprintf("'customer-%08d@example.com'", i) },
+			sortKey: func(i int) string { return fmt.Sprintf("customer-%08d@example.com", i) },
+			order:   order,
+		}
+	}
+	compositeKey := func(order []int) pkType {
+		return pkType{
+			columns: "a int, b int, primary key (a, b)",
+			key:     func(i int) []interface{} { return []interface{}{i / 100, i % 100} },
+			literal: func(i int) string { return fmt.Sprintf("%d, %d", i/100, i%100) },
+			sortKey: func(i int) string { return fmt.Sprintf("%010d %010d", i/100, i%100) },
+			order:   order,
+		}
+	}
+
+	// auto_increment numbers rows in insertion order, so row i of the
+	// ascending order gets key i+1, and a random order would only
+	// shuffle the other columns
+	autoIncrement := intKey("id int primary key auto_increment", ascending)
+	autoIncrement.name = "int auto_increment"
+	autoIncrement.generated = true
+	pkTypes := []pkType{autoIncrement}
+	for _, o := range []struct {
+		name  string
+		order []int
+	}{
+		{name: "ascending", order: ascending},
+		{name: "random order", order: shuffled},
+	} {
+		for _, t := range []struct {
+			name string
+			pkType
+		}{
+			{name: "int", pkType: intKey("id int primary key", o.order)},
+			{name: "sequential binary(16)", pkType: binaryKey(seqUUID, o.order)},
+			{name: "random binary(16)", pkType: binaryKey(func(i int) []byte { return uuids[i] }, o.order)},
+			{name: "varchar(40)", pkType: varcharKey(o.order)},
+			{name: "composite (int, int)", pkType: compositeKey(o.order)},
+		} {
+			t.pkType.name = fmt.Sprintf("%s %s", t.name, o.name)
+			pkTypes = append(pkTypes, t.pkType)
+		}
+	}
+
+	report := &strings.Builder{}
+	report.WriteString(fmt.Sprintf("%-40s %14s %12s %12s %8s %10s\n", "primary key", "insert ns/op", "point ns/op", "range ns/op", "nodes", "bytes"))
+	for _, pt := range pkTypes {
+		e, ctx := setupMemDB()
+		create := fmt.Sprintf("use test; create table t (%s, y int, z int)", pt.columns)
+		runSetup(e, ctx, create)
+
+		// one statement per batch, in insertion order
+		inserts := &strings.Builder{}
+		for start := 0; start < pkTypeRows; start += pkTypeBatch {
+			batch := pt.order[start : start+pkTypeBatch]
+			if pt.generated {
+				insertRows(inserts, "t (y, z)", len(batch), func(j int) string {
+					return fmt.Sprintf("%d, %d", batch[j], batch[j])
+				})
+				continue
+			}
+			insertRows(inserts, "t", len(batch), func(j int) string {
+				i := batch[j]
+				return fmt.Sprintf("%s, %d, %d", pt.literal(i), i, i)
+			})
+		}
+		insertSQL := strings.TrimSuffix(inserts.String(), ";\n")
+
+		// each insert starts from an empty table, and the last reset
+		// leaves it empty for the reads
+		insertNs := runTimedBench(b, fmt.Sprintf("%s insert", pt.name), func(int) {
+			runSetup(e, ctx, insertSQL)
+		}, func() {
+			runSetup(e, ctx, "drop table t; "+create)
+		})
+		runSetup(e, ctx, insertSQL)
+
+		t, db, err := e.Analyzer.Catalog.Table(ctx, "test", "t")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+		indexable, ok := t.(sql.IndexAddressableTable)
+		if !ok {
+			log.Fatalf("t not index addressable")
+		}
+		indexes, err := indexable.GetIndexes(ctx)
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+		pkIdx := indexes[0]
+		colTypes := pkIdx.ColumnExpressionTypes()
+
+		// keysInOrder lists row ids in storage order, so a range scan
+		// reads a contiguous tenth of the table for every key type
+		keysInOrder := append([]int{}, ascending...)
+		sort.Slice(keysInOrder, func(i, j int) bool {
+			return pt.sortKey(keysInOrder[i]) < pt.sortKey(keysInOrder[j])
+		})
+		lo, hi := keysInOrder[pkTypeRows/2], keysInOrder[pkTypeRows/2+pkTypeRows/10-1]
+
+		keyRange := func(lo, hi int) sql.Range {
+			loKey, hiKey := pt.key(lo), pt.key(hi)
+			var rng sql.Range
+			for c := range loKey {
+				if c < len(loKey)-1 && loKey[c] != hiKey[c] {
+					// a composite range is bounded by its first column
+					rng = append(rng, sql.ClosedRangeColumnExpr(loKey[c], hiKey[c], colTypes[c].Type))
+					for c++; c < len(loKey); c++ {
+						rng = append(rng, sql.AllRangeColumnExpr(colTypes[c].Type))
+					}
+					break
+				}
+				rng = append(rng, sql.ClosedRangeColumnExpr(loKey[c], hiKey[c], colTypes[c].Type))
+			}
+			return rng
+		}
+		lookup := func(rng sql.Range) sql.Node {
+			return mustStaticIndexedAccessForResolvedTable(
+				plan.NewResolvedTable(t, db, nil),
+				sql.IndexLookup{
+					Index:  pkIdx,
+					Ranges: sql.RangeCollection{rng},
+				})
+		}
+
+		point := lookup(keyRange(pkTypeRows/3, pkTypeRows/3))
+		scan := lookup(keyRange(lo, hi))
+		if n := len(sortedRowStrings(ctx, point)); n != 1 {
+			log.Fatalf("%s: point lookup returned %d rows\n", pt.name, n)
+		}
+		if n := len(sortedRowStrings(ctx, scan)); n != pkTypeRows/10 {
+			log.Fatalf("%s: range scan returned %d rows\n", pt.name, n)
+		}
+		pointNs := runOneBench(b, ctx, fmt.Sprintf("%s point lookup", pt.name), point)
+		rangeNs := runOneBench(b, ctx, fmt.Sprintf("%s range scan", pt.name), scan)
+
+		nodes, bytes := tableStorage(ctx, t)
+		report.WriteString(fmt.Sprintf("%-40s %14d %12d %12d %8d %10d\n", pt.name, insertNs, pointNs, rangeNs, nodes, bytes))
+	}
+	log.Print(report.String())
+}
@@ -0,0 +1,281 @@
+package query_faq_toy
+
+import (
+	"context"
+	dbsql "database/sql"
+	"fmt"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/utils/config"
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/mysql"
+	_ "github.com/go-sql-driver/mysql"
+	"log"
+	"strings"
+	"testing"
+)
+
+// preparedParams is the number of parameter sets each prepared
+// statement benchmark rotates through, so the cached plan is reused
+// with values that change on every execution.
+const preparedParams = 16
+
+// preparedQuery is one FAQ query with ? placeholders. params(i) returns
+// the values of parameter set i, in placeholder order.
+type preparedQuery struct {
+	name   string
+	query  string
+	params func(i int) []int64
+}
+
+// literal returns |q|'s SQL with parameter set |i| written in place of
+// the placeholders.
+func (q preparedQuery) literal(i int) string {
+	ret := q.query
+	for _, v := range q.params(i) {
+		ret = strings.Replace(ret, "?", fmt.Sprintf("%d", v), 1)
+	}
+	return ret
+}
+
+// bindings returns parameter set |i| as the engine's bindings, which
+// are named v1, v2... in placeholder order.
+func (q preparedQuery) bindings(i int) map[string]sql.Expression {
+	ret := make(map[string]sql.Expression)
+	for j, v := range q.params(i) {
+		ret[fmt.Sprintf("v%d", j+1)] = expression.NewLiteral(v, types.Int64)
+	}
+	return ret
+}
+
+func (q preparedQuery) args(i int) []interface{} {
+	var ret []interface{}
+	for _, v := range q.params(i) {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// BenchmarkPreparedStatement compares running the FAQ queries as
+// literal SQL, which parses and analyzes every execution, against
+// prepared statements that are analyzed once and executed with new
+// parameters. Each comparison runs through the engine's prepared
+// statement cache and through a MySQL server with a client that
+// prepares statements on the server.
+func BenchmarkPreparedStatement(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int, z int, key(y));")
+	s.WriteString("create table uv (u int primary key, v int, w int, key(v));")
+	for _, table := range []string{"xy", "uv"} {
+		insertRows(s, table, 10000, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d", i, i%100, i)
+		})
+	}
+	runSetup(e, ctx, s.String())
+
+	queries := []preparedQuery{
+		{
+			name:   "point lookup",
+			query:  "select * from xy where x = ?",
+			params: func(i int) []int64 { return []int64{int64(i * 601)} },
+		},
+		{
+			name:   "index range",
+			query:  "select * from xy where y between ? and ?",
+			params: func(i int) []int64 { return []int64{int64(i), int64(i + 2)} },
+		},
+		{
+			name:  "in list",
+			query: "select * from xy where x in (?, ?, ?, ?, ?)",
+			params: func(i int) []int64 {
+				return []int64{int64(i), int64(i + 100), int64(i + 200), int64(i + 300), int64(i + 400)}
+			},
+		},
+		{
+			name:   "lookup join",
+			query:  "select count(*) from xy join uv on xy.x = uv.u where xy.y = ?",
+			params: func(i int) []int64 { return []int64{int64(i)} },
+		},
+		{
+			name:   "semi join",
+			query:  "select * from xy where x in (select u from uv where v = ?)",
+			params: func(i int) []int64 { return []int64{int64(i)} },
+		},
+		{
+			name:   "scalar subquery",
+			query:  "select x, (select max(w) from uv where u = xy.x) from xy where x < ?",
+			params: func(i int) []int64 { return []int64{int64(50 + i)} },
+		},
+	}
+
+	db := startPreparedServer(b, e, ctx)
+	defer db.Close()
+
+	// each prepared column shows its ns/op and the share of the literal
+	// column to its left that it saves
+	report := &strings.Builder{}
+	report.WriteString(fmt.Sprintf("%-16s %14s %14s %14s %14s %14s %14s  %s\n", "query", "analyze", "reanalyze", "query", "cached query", "wire query", "wire prepared", "indexed literal/prepared"))
+	for _, q := range queries {
+		// the plan of a cached statement, with the first and last
+		// parameter sets bound, against the literal plans
+		_, err := e.PrepareQuery(ctx, q.query)
+		if err != nil {
+			log.Fatalf("preparing query '%s': %s\n", q.query, err)
+		}
+		var indexed []string
+		for _, i := range []int{0, preparedParams - 1} {
+			literal := logAnalyzedPlan(e, ctx, q.literal(i))
+			prepared := preparedPlan(e, ctx, q.query, q.bindings(i))
+			log.Printf("prepared '%s' with %v:\n%s\n", q.query, q.params(i), sql.DebugString(prepared))
+			checkEquivalent(ctx, fmt.Sprintf("%s parameters %v", q.name, q.params(i)), literal, prepared)
+			indexed = append(indexed, fmt.Sprintf("%t/%t", hasIndexedAccess(literal), hasIndexedAccess(prepared)))
+		}
+
+		// analysis of the literal query against the post-prepare rules
+		// run on the cached plan
+		analyzeNs := runPreparedBench(b, fmt.Sprintf("%s analyze", q.name), func(i int) {
+			if _, err := e.AnalyzeQuery(ctx, q.literal(i)); err != nil {
+				log.Fatalf("analyzing query '%s': %s\n", q.literal(i), err)
+			}
+		})
+		reanalyzeNs := runPreparedBench(b, fmt.Sprintf("%s reanalyze", q.name), func(i int) {
+			preparedPlan(e, ctx, q.query, q.bindings(i))
+		})
+
+		literalNs := runPreparedBench(b, fmt.Sprintf("%s literal", q.name), func(i int) {
+			runQuery(e, ctx, q.literal(i))
+		})
+		cachedNs := runPreparedBench(b, fmt.Sprintf("%s cached", q.name), func(i int) {
+			sch, iter, err := e.QueryWithBindings(ctx, q.query, q.bindings(i))
+			if err != nil {
+				log.Fatalf("executing prepared query '%s': %s\n", q.query, err)
+			}
+			if _, err := sql.RowIterToRows(ctx, sch, iter); err != nil {
+				log.Fatalf("executing prepared query '%s': %s\n", q.query, err)
+			}
+		})
+		e.PreparedDataCache.UncacheStmt(ctx.Session.ID(), q.query)
+
+		wireLiteralNs := runPreparedBench(b, fmt.Sprintf("%s wire literal", q.name), func(i int) {
+			readAllRows(db.Query(q.literal(i)))
+		})
+		stmt, err := db.Prepare(q.query)
+		if err != nil {
+			log.Fatalf("preparing query '%s': %s\n", q.query, err)
+		}
+		wirePreparedNs := runPreparedBench(b, fmt.Sprintf("%s wire prepared", q.name), func(i int) {
+			readAllRows(stmt.Query(q.args(i)...))
+		})
+		stmt.Close()
+
+		report.WriteString(fmt.Sprintf("%-16s %14s %14s %14s %14s %14s %14s  %s\n", q.name,
+			fmt.Sprint(analyzeNs), savedNs(analyzeNs, reanalyzeNs),
+			fmt.Sprint(literalNs), savedNs(literalNs, cachedNs),
+			fmt.Sprint(wireLiteralNs), savedNs(wireLiteralNs, wirePreparedNs),
+			strings.Join(indexed, " ")))
+	}
+	log.Print(report.String())
+}
+
+// savedNs renders |after| with the share of |before| it saves.
+func savedNs(before, after int64) string {
+	return fmt.Sprintf("%d (%.0f%%)", after, 100*float64(before-after)/float64(before))
+}
+
+// runPreparedBench times |run| with a new parameter set on every
+// iteration.
+func runPreparedBench(b *testing.B, name string, run func(i int)) int64 {
+	return runTimedBench(b, name, func(n int) {
+		run(n % preparedParams)
+	}, nil)
+}
+
+// preparedPlan returns the plan the engine executes for the cached
+// prepared statement |q| with |bindings|: a copy of the cached plan with
+// the bindings applied, and the analyzer's post-prepare rules run on it.
+func preparedPlan(e *sqle.Engine, ctx *sql.Context, q string, bindings map[string]sql.Expression) sql.Node {
+	cached, ok := e.PreparedDataCache.GetCachedStmt(ctx.Session.ID(), q)
+	if !ok {
+		log.Fatalf("query '%s' is not prepared\n", q)
+	}
+	n, err := analyzer.DeepCopyNode(cached)
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+	n, _, err = plan.ApplyBindings(n, bindings)
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+	n, _, err = e.Analyzer.AnalyzePrepared(ctx, n, nil)
+	if err != nil {
+		log.Fatalf("analyzing prepared query '%s': %s\n", q, err)
+	}
+	return n
+}
+
+// startPreparedServer serves |e| over the MySQL protocol on a local
+// port, and returns a client connected to the test database. Server
+// sessions are Dolt sessions on the provider of |ctx|'s session. The
+// client keeps one connection, so statements it prepares stay on the
+// server session that prepared them.
+func startPreparedServer(b *testing.B, e *sqle.Engine, ctx *sql.Context) *dbsql.DB {
+	doltSession := ctx.Session.(*dsess.DoltSession)
+	sessionBuilder := func(ctx context.Context, c *mysql.Conn, addr string) (sql.Session, error) {
+		base, err := server.DefaultSessionBuilder(ctx, c, addr)
+		if err != nil {
+			return nil, err
+		}
+		return dsess.NewDoltSession(base.(*sql.BaseSession), doltSession.Provider(), config.NewMapConfig(map[string]string{}), doltSession.GetController())
+	}
+	srv, err := server.NewServer(server.Config{Protocol: "tcp", Address: "127.0.0.1:0"}, e, sessionBuilder, nil)
+	if err != nil {
+		log.Fatalf("starting server: %s\n", err)
+	}
+	go srv.Start()
+	b.Cleanup(func() {
+		srv.Close()
+	})
+
+	db, err := dbsql.Open("mysql", fmt.Sprintf("root@tcp(%s)/test", srv.Listener.Addr()))
+	if err != nil {
+		log.Fatalf("connecting to server: %s\n", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	if err := db.Ping(); err != nil {
+		log.Fatalf("connecting to server: %s\n", err)
+	}
+	return db
+}
+
+// readAllRows reads and discards the result of a client query.
+func readAllRows(rows *dbsql.Rows, err error) {
+	if err != nil {
+		log.Fatalf("client query: %s\n", err)
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		log.Fatalf("client query: %s\n", err)
+	}
+	vals := make([]interface{}, len(cols))
+	for i := range vals {
+		vals[i] = new(dbsql.RawBytes)
+	}
+	for rows.Next() {
+		if err := rows.Scan(vals...); err != nil {
+			log.Fatalf("client query: %s\n", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatalf("client query: %s\n", err)
+	}
+}
@@ -116,3 +116,149 @@ func BenchmarkPrune(b *testing.B) {
 		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
 	}
 }
+
+// pruneWideRows is the number of rows in each BenchmarkPruneWide table.
+const pruneWideRows = 500
+
+// BenchmarkPruneWide repeats the BenchmarkPrune comparisons on tables
+// with 4 to 200 columns of either ints or 100 character strings, to show
+// how the benefit of pruning grows with the width of the row.
+func BenchmarkPruneWide(b *testing.B) {
+	text := strings.Repeat("a", 100)
+	widths := []struct {
+		name  string
+		typ   string
+		value string
+	}{
+		{name: "int", typ: "int", value: "%d"},
+		{name: "varchar", typ: "varchar(255)", value: "'" + text + "'"},
+	}
+
+	report := &strings.Builder{}
+	report.WriteString(fmt.Sprintf("%-20s %13s %13s %6s %13s %13s %6s %10s %10s\n", "table", "scan pre", "scan post", "ratio", "join pre", "join post", "ratio", "row bytes", "pruned"))
+	for _, w := range widths {
+		for _, cols := range []int{4, 16, 64, 200} {
+			e, ctx := setupMemDB()
+
+			// xy and uv hold the same rows, a primary key followed by
+			// cols-1 payload columns of the given type
+			columns := func(pk, prefix string) string {
+				defs := []string{fmt.Sprintf("%s int primary key", pk)}
+				for c := 1; c < cols; c++ {
+					defs = append(defs, fmt.Sprintf("%s%d %s", prefix, c, w.typ))
+				}
+				return strings.Join(defs, ", ")
+			}
+			row := func(i int) string {
+				vals := []string{fmt.Sprintf("%d", i)}
+				for c := 1; c < cols; c++ {
+					vals = append(vals, strings.Replace(w.value, "%d", fmt.Sprintf("%d", i), 1))
+				}
+				return strings.Join(vals, ", ")
+			}
+			s := &strings.Builder{}
+			s.WriteString("use test;")
+			s.WriteString(fmt.Sprintf("create table xy (%s);", columns("x", "c")))
+			s.WriteString(fmt.Sprintf("create table uv (%s);", columns("u", "d")))
+			insertRows(s, "xy", pruneWideRows, row)
+			insertRows(s, "uv", pruneWideRows, row)
+			runSetup(e, ctx, s.String())
+
+			if w.name == "varchar" && cols == 200 {
+				logAnalyzedPlan(e, ctx, "select x, u from xy join uv on x = u")
+			}
+
+			xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+
+			uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+
+			uvIndexable, ok := uv.(sql.IndexAddressableTable)
+			if !ok {
+				log.Fatalf("uv not index addressable")
+			}
+			uvIndexes, err := uvIndexable.GetIndexes(ctx)
+			uvPk := uvIndexes[0]
+
+			// join looks up uv by primary key for every row of xy. |u| is
+			// the position of u in the joined row.
+			join := func(xy, uv sql.Table, u int) sql.Node {
+				return plan.NewJoin(
+					plan.NewResolvedTable(xy, db, nil),
+					mustIndexedAccessForResolvedTable(
+						plan.NewResolvedTable(uv, db, nil),
+						plan.NewLookupBuilder(
+							uvPk,
+							[]sql.Expression{
+								expression.NewGetField(0, types.Int64, "x", false),
+							},
+							[]bool{false},
+						),
+					),
+					plan.JoinTypeLookup,
+					expression.NewEquals(
+						expression.NewGetField(0, types.Int64, "x", false),
+						expression.NewGetField(u, types.Int64, "u", false),
+					),
+				)
+			}
+			prunedXy := xy.(*sqle.AlterableDoltTable).WithProjections([]string{"x"})
+			prunedUv := uv.(*sqle.AlterableDoltTable).WithProjections([]string{"u"})
+
+			name := fmt.Sprintf("%d %s columns", cols, w.name)
+			scanPre := plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetField(0, types.Int64, "x", false),
+				},
+				plan.NewResolvedTable(xy, db, nil),
+			)
+			scanPost := plan.NewResolvedTable(prunedXy, db, nil)
+			joinPre := plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetField(0, types.Int64, "x", false),
+					expression.NewGetField(cols, types.Int64, "u", false),
+				},
+				join(xy, uv, cols),
+			)
+			joinPost := join(prunedXy, prunedUv, 1)
+			checkEquivalent(ctx, name+" scan", scanPre, scanPost)
+			checkEquivalent(ctx, name+" join", joinPre, joinPost)
+
+			scanPreNs := runOneBench(b, ctx, fmt.Sprintf("%s scan pre-opt", name), scanPre)
+			scanPostNs := runOneBench(b, ctx, fmt.Sprintf("%s scan post-opt", name), scanPost)
+			joinPreNs := runOneBench(b, ctx, fmt.Sprintf("%s join pre-opt", name), joinPre)
+			joinPostNs := runOneBench(b, ctx, fmt.Sprintf("%s join post-opt", name), joinPost)
+
+			// the join intermediate is the row the join passes to its
+			// parent, before and after pruning
+			report.WriteString(fmt.Sprintf("%-20s %13d %13d %5.1fx %13d %13d %5.1fx %10d %10d\n",
+				name,
+				scanPreNs, scanPostNs, float64(scanPreNs)/float64(scanPostNs),
+				joinPreNs, joinPostNs, float64(joinPreNs)/float64(joinPostNs),
+				rowBytes(firstRow(ctx, join(xy, uv, cols))), rowBytes(firstRow(ctx, joinPost)),
+			))
+		}
+	}
+	log.Print(report.String())
+}
+
+// rowBytes approximates the in-memory payload of |row|: 8 bytes per
+// fixed width value plus the length of every string.
+func rowBytes(row sql.Row) int {
+	size := 0
+	for _, v := range row {
+		switch v := v.(type) {
+		case string:
+			size += len(v)
+		case nil:
+		default:
+			size += 8
+		}
+	}
+	return size
+}
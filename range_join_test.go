@@ -0,0 +1,264 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+// BenchmarkRangeJoin compares the range join x between v and r as a
+// nested loop join and as a lookup join that reads an index range per
+// outer row. The pinned GMS version predates the range heap join, and
+// LookupBuilder only builds equality lookups, so rangeLookup stands in
+// for the range read.
+func BenchmarkRangeJoin(b *testing.B) {
+	// uv row i covers xy.x in [i*stride, i*stride+width]; ranges overlap
+	// when width > stride
+	for _, cfg := range []struct {
+		width  int
+		stride int
+	}{
+		{width: 1, stride: 10},
+		{width: 10, stride: 10},
+		{width: 50, stride: 5},
+	} {
+		e, ctx := setupMemDB()
+
+		s := &strings.Builder{}
+		s.WriteString("use test;")
+		s.WriteString("create table xy (x int primary key, y int, z int, w int);")
+		s.WriteString("create table uv (u int primary key, v int, r int, s int);")
+		insertRows(s, "xy", 1000, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+		})
+		insertRows(s, "uv", 100, func(i int) string {
+			return fmt.Sprintf("%d, %d, %d, %d", i, i*cfg.stride, i*cfg.stride+cfg.width, i)
+		})
+		runSetup(e, ctx, s.String())
+
+		logAnalyzedPlan(e, ctx, "select * from uv join xy on x between v and r")
+
+		xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+
+		uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+
+		xyIndexable, ok := xy.(sql.IndexAddressableTable)
+		if !ok {
+			log.Fatalf("xy not index addressable")
+		}
+		xyIndexes, err := xyIndexable.GetIndexes(ctx)
+		xyPk := xyIndexes[0]
+
+		tests := []struct {
+			name string
+			pre  sql.Node
+			post sql.Node
+		}{
+			{
+				name: fmt.Sprintf("between join width %d stride %d", cfg.width, cfg.stride),
+				pre: plan.NewJoin(
+					plan.NewResolvedTable(uv, db, nil),
+					plan.NewResolvedTable(xy, db, nil),
+					plan.JoinTypeInner,
+					expression.NewBetween(
+						expression.NewGetField(4, types.Int64, "x", false),
+						expression.NewGetField(1, types.Int64, "v", true),
+						expression.NewGetField(2, types.Int64, "r", true),
+					),
+				),
+				post: plan.NewJoin(
+					plan.NewResolvedTable(uv, db, nil),
+					&rangeLookup{
+						rt:    plan.NewResolvedTable(xy, db, nil),
+						index: xyPk,
+						lower: expression.NewGetField(1, types.Int64, "v", true),
+						upper: expression.NewGetField(2, types.Int64, "r", true),
+					},
+					plan.JoinTypeLookup,
+					expression.NewBetween(
+						expression.NewGetField(4, types.Int64, "x", false),
+						expression.NewGetField(1, types.Int64, "v", true),
+						expression.NewGetField(2, types.Int64, "r", true),
+					),
+				),
+			},
+		}
+
+		for _, bb := range tests {
+			checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+			runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+		}
+	}
+}
+
+// BenchmarkInequalityJoin compares the single inequality join
+// x < u as a nested loop join and as a lookup join that reads the
+// primary key range of xy below each u.
+func BenchmarkInequalityJoin(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int, z int, w int);")
+	s.WriteString("create table uv (u int primary key, v int, r int, s int);")
+	insertRows(s, "xy", 1000, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+	})
+	insertRows(s, "uv", 100, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+	})
+	runSetup(e, ctx, s.String())
+
+	logAnalyzedPlan(e, ctx, "select * from uv join xy on x < u")
+
+	xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	xyIndexable, ok := xy.(sql.IndexAddressableTable)
+	if !ok {
+		log.Fatalf("xy not index addressable")
+	}
+	xyIndexes, err := xyIndexable.GetIndexes(ctx)
+	xyPk := xyIndexes[0]
+
+	tests := []struct {
+		name string
+		pre  sql.Node
+		post sql.Node
+	}{
+		{
+			name: "less than join",
+			pre: plan.NewJoin(
+				plan.NewResolvedTable(uv, db, nil),
+				plan.NewResolvedTable(xy, db, nil),
+				plan.JoinTypeInner,
+				expression.NewLessThan(
+					expression.NewGetField(4, types.Int64, "x", false),
+					expression.NewGetField(0, types.Int64, "u", false),
+				),
+			),
+			post: plan.NewJoin(
+				plan.NewResolvedTable(uv, db, nil),
+				&rangeLookup{
+					rt:    plan.NewResolvedTable(xy, db, nil),
+					index: xyPk,
+					upper: expression.NewGetField(0, types.Int64, "u", false),
+				},
+				plan.JoinTypeLookup,
+				expression.NewLessThan(
+					expression.NewGetField(4, types.Int64, "x", false),
+					expression.NewGetField(0, types.Int64, "u", false),
+				),
+			),
+		},
+	}
+
+	for _, bb := range tests {
+		checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+	}
+}
+
+// rangeLookup reads the closed range [lower, upper] of |index| for
+// every outer row. A nil bound is unbounded. The join filter still
+// applies, so a strict inequality can use a closed range.
+type rangeLookup struct {
+	rt    *plan.ResolvedTable
+	index sql.Index
+	lower sql.Expression
+	upper sql.Expression
+}
+
+var _ sql.Node = (*rangeLookup)(nil)
+
+func (l *rangeLookup) Resolved() bool {
+	return true
+}
+
+func (l *rangeLookup) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("RangeLookup(%s)", l.rt.Name())
+	_ = pr.WriteChildren(
+		fmt.Sprintf("index: [%s]", strings.Join(l.index.Expressions(), ",")),
+		fmt.Sprintf("lower: %s", l.lower),
+		fmt.Sprintf("upper: %s", l.upper),
+	)
+	return pr.String()
+}
+
+func (l *rangeLookup) Schema() sql.Schema {
+	return l.rt.Schema()
+}
+
+func (l *rangeLookup) Children() []sql.Node {
+	return nil
+}
+
+func (l *rangeLookup) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(l, len(children), 0)
+	}
+	return l, nil
+}
+
+func (l *rangeLookup) CheckPrivileges(ctx *sql.Context, opChecker sql.PrivilegedOperationChecker) bool {
+	return true
+}
+
+func (l *rangeLookup) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	typ := l.index.ColumnExpressionTypes()[0].Type
+	var lo, hi interface{}
+	var err error
+	if l.lower != nil {
+		if lo, err = l.lower.Eval(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+	if l.upper != nil {
+		if hi, err = l.upper.Eval(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+
+	var rce sql.RangeColumnExpr
+	switch {
+	case l.lower != nil && lo == nil, l.upper != nil && hi == nil:
+		// NULL bounds never match
+		return sql.RowsToRowIter(), nil
+	case l.lower != nil && l.upper != nil:
+		rce = sql.ClosedRangeColumnExpr(lo, hi, typ)
+	case l.lower != nil:
+		rce = sql.GreaterOrEqualRangeColumnExpr(lo, typ)
+	case l.upper != nil:
+		rce = sql.LessOrEqualRangeColumnExpr(hi, typ)
+	default:
+		rce = sql.AllRangeColumnExpr(typ)
+	}
+
+	ita, err := plan.NewStaticIndexedAccessForResolvedTable(l.rt, sql.IndexLookup{
+		Index:  l.index,
+		Ranges: sql.RangeCollection{sql.Range{rce}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ita.RowIter(ctx, row)
+}
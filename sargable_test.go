@@ -0,0 +1,64 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+// BenchmarkSargable compares predicates that wrap an indexed column in
+// a function or conversion against rewrites that compare the bare
+// column, which the analyzer can turn into an index range. Both sides
+// are the analyzer's own plans.
+func BenchmarkSargable(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	// ts advances one minute per row, so each calendar day holds 1440
+	// rows
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table t (id int primary key, x int, ts datetime, key(x), key(ts));")
+	insertRows(s, "t", 10000, func(i int) string {
+		return fmt.Sprintf("%d, %d, date_add('2024-01-01', interval %d minute)", i, i, i)
+	})
+	runSetup(e, ctx, s.String())
+
+	tests := []struct {
+		name string
+		pre  string
+		post string
+	}{
+		{
+			name: "arithmetic on column",
+			pre:  "select * from t where x + 1 = 5",
+			post: "select * from t where x = 4",
+		},
+		{
+			name: "cast column to char",
+			pre:  "select * from t where cast(x as char) = '5'",
+			post: "select * from t where x = 5",
+		},
+		{
+			name: "int column vs string literal",
+			pre:  "select * from t where x = '5'",
+			post: "select * from t where x = 5",
+		},
+		{
+			name: "date of datetime column",
+			pre:  "select * from t where date(ts) = '2024-01-02'",
+			post: "select * from t where ts >= '2024-01-02' and ts < '2024-01-03'",
+		},
+	}
+
+	for _, bb := range tests {
+		pre := logAnalyzedPlan(e, ctx, bb.pre)
+		post := logAnalyzedPlan(e, ctx, bb.post)
+		if !hasIndexedAccess(post) {
+			log.Fatalf("%s: '%s' does not use an index\n", bb.name, bb.post)
+		}
+		log.Printf("%s: '%s' uses an index: %t\n", bb.name, bb.pre, hasIndexedAccess(pre))
+		checkEquivalent(ctx, bb.name, pre, post)
+		runBenchmarkComparison(b, ctx, bb.name, pre, post)
+	}
+}
@@ -0,0 +1,179 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function"
+	"github.com/dolthub/go-mysql-server/sql/expression/function/aggregation"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+func BenchmarkScalarSubquery(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	// every v in [0, 50) appears 20 times in uv, xy.x in [50, 100) has
+	// no match
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int, z int, w int);")
+	s.WriteString("create table uv (u int primary key, v int, r int, s int);")
+	insertRows(s, "xy", 100, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+	})
+	insertRows(s, "uv", 1000, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i%50, i, i)
+	})
+	runSetup(e, ctx, s.String())
+
+	for _, q := range []string{
+		"select x, (select count(*) from uv where v = x) from xy",
+		"select x, coalesce(g.c, 0) from xy left join (select v, count(*) c from uv group by v) g on g.v = x",
+		"select x, (select max(r) from uv where v = x) from xy",
+		"select x, g.m from xy left join (select v, max(r) m from uv group by v) g on g.v = x",
+	} {
+		logAnalyzedPlan(e, ctx, q)
+	}
+
+	xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	// correlated scalar subqueries see the outer xy row first, so uv.v
+	// is at 5 and uv.r is at 6
+	correlated := func(agg sql.Aggregation, text string) sql.Node {
+		return plan.NewProject(
+			[]sql.Expression{
+				expression.NewGetField(0, types.Int64, "x", false),
+				plan.NewSubquery(
+					plan.NewGroupBy(
+						[]sql.Expression{agg},
+						nil,
+						plan.NewFilter(
+							expression.NewEquals(
+								expression.NewGetField(5, types.Int64, "v", true),
+								expression.NewGetField(0, types.Int64, "x", false),
+							),
+							plan.NewResolvedTable(uv, db, nil),
+						),
+					),
+					text,
+				),
+			},
+			plan.NewResolvedTable(xy, db, nil),
+		)
+	}
+
+	grouped := func(agg sql.Aggregation, text string) *plan.SubqueryAlias {
+		return plan.NewSubqueryAlias("g", text,
+			plan.NewGroupBy(
+				[]sql.Expression{
+					expression.NewGetField(1, types.Int64, "v", true),
+					agg,
+				},
+				[]sql.Expression{
+					expression.NewGetField(1, types.Int64, "v", true),
+				},
+				plan.NewResolvedTable(uv, db, nil),
+			),
+		)
+	}
+
+	// decorrelated joins xy onto a grouped derived table. When |cached|
+	// the derived table is materialized once into a hash table, otherwise
+	// a nested loop re-runs the GroupBy for every xy row.
+	decorrelated := func(right *plan.SubqueryAlias, out sql.Expression, cached bool) sql.Node {
+		var join sql.Node
+		cond := expression.NewEquals(
+			expression.NewGetField(4, types.Int64, "v", true),
+			expression.NewGetField(0, types.Int64, "x", false),
+		)
+		if cached {
+			join = plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				plan.NewHashLookup(
+					plan.NewCachedResults(right.WithCachedResults()),
+					expression.NewGetField(0, types.Int64, "v", true),
+					expression.NewGetField(0, types.Int64, "x", false),
+				),
+				plan.JoinTypeLeftOuterHash,
+				cond,
+			)
+		} else {
+			join = plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				right,
+				plan.JoinTypeLeftOuter,
+				cond,
+			)
+		}
+		return plan.NewProject(
+			[]sql.Expression{
+				expression.NewGetField(0, types.Int64, "x", false),
+				out,
+			},
+			join,
+		)
+	}
+
+	countOut := func() sql.Expression {
+		out, err := function.NewCoalesce(
+			expression.NewGetField(5, types.Int64, "c", true),
+			expression.NewLiteral(int64(0), types.Int64),
+		)
+		if err != nil {
+			log.Fatalf("%s\n", err)
+		}
+		return out
+	}
+	maxOut := expression.NewGetField(5, types.Int64, "m", true)
+
+	countStar := func() sql.Aggregation {
+		return aggregation.NewCount(expression.NewLiteral(1, types.Int64))
+	}
+	maxR := func(idx int) sql.Aggregation {
+		return aggregation.NewMax(expression.NewGetField(idx, types.Int64, "r", true))
+	}
+
+	tests := []struct {
+		name string
+		pre  sql.Node
+		post sql.Node
+	}{
+		{
+			name: "correlated count vs left join",
+			pre:  correlated(countStar(), "(select count(*) from uv where v = x)"),
+			post: decorrelated(grouped(countStar(), "select v, count(*) c from uv group by v"), countOut(), false),
+		},
+		{
+			name: "correlated count vs cached left hash join",
+			pre:  correlated(countStar(), "(select count(*) from uv where v = x)"),
+			post: decorrelated(grouped(countStar(), "select v, count(*) c from uv group by v"), countOut(), true),
+		},
+		{
+			name: "correlated max vs left join",
+			pre:  correlated(maxR(6), "(select max(r) from uv where v = x)"),
+			post: decorrelated(grouped(maxR(2), "select v, max(r) m from uv group by v"), maxOut, false),
+		},
+		{
+			name: "correlated max vs cached left hash join",
+			pre:  correlated(maxR(6), "(select max(r) from uv where v = x)"),
+			post: decorrelated(grouped(maxR(2), "select v, max(r) m from uv group by v"), maxOut, true),
+		},
+	}
+
+	for _, bb := range tests {
+		checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+	}
+}
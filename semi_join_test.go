@@ -0,0 +1,166 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"log"
+	"strings"
+	"testing"
+)
+
+func BenchmarkSemiJoin(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	// every v in [0, 50) appears 20 times in uv, so an inner join
+	// duplicates xy rows that IN would return once
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int, z int, w int);")
+	s.WriteString("create table uv (u int primary key, v int, r int, s int, key(v));")
+	insertRows(s, "xy", 100, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+	})
+	insertRows(s, "uv", 1000, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i%50, i, i)
+	})
+	runSetup(e, ctx, s.String())
+
+	for _, q := range []string{
+		"select * from xy where x in (select v from uv where r < 500)",
+		"select distinct xy.* from xy join uv on x = v where r < 500",
+		"select xy.* from xy join uv on x = v where r < 500",
+	} {
+		log.Printf("'%s' returns %d rows\n", q, len(sortedRowStrings(ctx, logAnalyzedPlan(e, ctx, q))))
+	}
+
+	xy, db, err := e.Analyzer.Catalog.Table(ctx, "test", "xy")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	uv, db, err := e.Analyzer.Catalog.Table(ctx, "test", "uv")
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	uvIndexable, ok := uv.(sql.IndexAddressableTable)
+	if !ok {
+		log.Fatalf("uv not index addressable")
+	}
+	uvIndexes, err := uvIndexable.GetIndexes(ctx)
+	vIdx := uvIndexes[1]
+
+	rFilter := func(idx int) sql.Expression {
+		return expression.NewLessThan(
+			expression.NewGetField(idx, types.Int64, "r", true),
+			expression.NewLiteral(500, types.Int64),
+		)
+	}
+	joinCond := expression.NewEquals(
+		expression.NewGetField(0, types.Int64, "x", false),
+		expression.NewGetField(5, types.Int64, "v", true),
+	)
+
+	// the subquery sees the outer xy row first
+	inSubquery := plan.NewFilter(
+		plan.NewInSubquery(
+			expression.NewGetField(0, types.Int64, "x", false),
+			plan.NewSubquery(
+				plan.NewProject(
+					[]sql.Expression{
+						expression.NewGetField(5, types.Int64, "v", true),
+					},
+					plan.NewFilter(rFilter(6), plan.NewResolvedTable(uv, db, nil)),
+				),
+				"(select v from uv where r < 500)",
+			).WithCachedResults(),
+		),
+		plan.NewResolvedTable(xy, db, nil),
+	)
+	semiLookup := plan.NewJoin(
+		plan.NewResolvedTable(xy, db, nil),
+		plan.NewFilter(
+			rFilter(2),
+			mustIndexedAccessForResolvedTable(
+				plan.NewResolvedTable(uv, db, nil),
+				plan.NewLookupBuilder(
+					vIdx,
+					[]sql.Expression{
+						expression.NewGetField(0, types.Int64, "x", false),
+					},
+					[]bool{false},
+				),
+			),
+		),
+		plan.JoinTypeSemiLookup,
+		joinCond,
+	)
+	semiHash := plan.NewJoin(
+		plan.NewResolvedTable(xy, db, nil),
+		plan.NewHashLookup(
+			plan.NewCachedResults(plan.NewFilter(rFilter(2), plan.NewResolvedTable(uv, db, nil))),
+			expression.NewGetField(1, types.Int64, "v", true),
+			expression.NewGetField(0, types.Int64, "x", false),
+		),
+		plan.JoinTypeSemiHash,
+		joinCond,
+	)
+	innerDistinct := plan.NewDistinct(
+		plan.NewProject(
+			[]sql.Expression{
+				expression.NewGetField(0, types.Int64, "x", false),
+				expression.NewGetField(1, types.Int64, "y", true),
+				expression.NewGetField(2, types.Int64, "z", true),
+				expression.NewGetField(3, types.Int64, "w", true),
+			},
+			plan.NewJoin(
+				plan.NewResolvedTable(xy, db, nil),
+				plan.NewFilter(
+					rFilter(2),
+					mustIndexedAccessForResolvedTable(
+						plan.NewResolvedTable(uv, db, nil),
+						plan.NewLookupBuilder(
+							vIdx,
+							[]sql.Expression{
+								expression.NewGetField(0, types.Int64, "x", false),
+							},
+							[]bool{false},
+						),
+					),
+				),
+				plan.JoinTypeLookup,
+				joinCond,
+			),
+		),
+	)
+
+	tests := []struct {
+		name string
+		pre  sql.Node
+		post sql.Node
+	}{
+		{
+			name: "in subquery vs semi lookup join",
+			pre:  inSubquery,
+			post: semiLookup,
+		},
+		{
+			name: "in subquery vs semi hash join",
+			pre:  inSubquery,
+			post: semiHash,
+		},
+		{
+			name: "inner join distinct vs semi lookup join",
+			pre:  innerDistinct,
+			post: semiLookup,
+		},
+	}
+
+	for _, bb := range tests {
+		checkEquivalent(ctx, bb.name, bb.pre, bb.post)
+		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
+	}
+}
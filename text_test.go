@@ -2,9 +2,12 @@ package query_faq_toy
 
 import (
 	"fmt"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
 	"log"
+	"math/rand"
 	"strings"
 	"testing"
 )
@@ -76,3 +79,103 @@ func BenchmarkText(b *testing.B) {
 		runBenchmarkComparison(b, ctx, bb.name, bb.pre, bb.post)
 	}
 }
+
+// textSizeBytes caps the payload of each BenchmarkTextSize table, so
+// tables of larger values hold fewer rows.
+const textSizeBytes = 4 << 20
+
+// BenchmarkTextSize stores values from 16 bytes to 4MB in string and
+// binary column types. TEXT and BLOB types are stored out of band as an
+// address into a separate tree, while VARCHAR and VARBINARY are stored
+// inline in the row, which caps them at the 64KB row limit. A longer row
+// panics when it is flushed instead of returning an error.
+func BenchmarkTextSize(b *testing.B) {
+	columnTypes := []struct {
+		name string
+		// max is the largest value size the type can hold
+		max int64
+		// typ returns the column definition for values of |size| bytes
+		typ func(size int) string
+	}{
+		{name: "varchar", max: 65000, typ: func(size int) string { return fmt.Sprintf("varchar(%d)", size) }},
+		{name: "varbinary", max: 65000, typ: func(size int) string { return fmt.Sprintf("varbinary(%d)", size) }},
+		{name: "text", max: 65535, typ: func(int) string { return "text" }},
+		{name: "blob", max: 65535, typ: func(int) string { return "blob" }},
+		{name: "longtext", max: 1<<32 - 1, typ: func(int) string { return "longtext" }},
+	}
+
+	report := &strings.Builder{}
+	report.WriteString(fmt.Sprintf("%-10s %-16s %5s %12s %12s %12s %12s %10s\n", "size", "type", "rows", "insert", "scan", "point", "pruned scan", "row bytes"))
+	for _, size := range []int{16, 1 << 10, 16000, 60000, 1 << 20, 4 << 20} {
+		rows := textSizeBytes / size
+		if rows > 1000 {
+			rows = 1000
+		} else if rows < 2 {
+			rows = 2
+		}
+		// every value is distinct, so content addressing cannot share
+		// out of band values between rows
+		r := rand.New(rand.NewSource(int64(size)))
+		value := make([]byte, size)
+		insert := &strings.Builder{}
+		insertRows(insert, "t", rows, func(i int) string {
+			for j := range value {
+				value[j] = 'a' + byte(r.Intn(26))
+			}
+			return fmt.Sprintf("%d, '%s', %d", i, value, i)
+		})
+		insertSQL := strings.TrimSuffix(insert.String(), ";\n")
+
+		for _, ct := range columnTypes {
+			if int64(size) > ct.max {
+				continue
+			}
+			e, ctx := setupMemDB()
+			typ := ct.typ(size)
+			runSetup(e, ctx, fmt.Sprintf("use test; create table t (id int primary key, v %s, n int)", typ))
+
+			name := fmt.Sprintf("%d bytes %s", size, ct.name)
+			insertNs := runOneQueryBench(b, e, ctx, name+" insert", insertSQL, "delete from t")
+			runQuery(e, ctx, insertSQL)
+
+			t, db, err := e.Analyzer.Catalog.Table(ctx, "test", "t")
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+			indexable, ok := t.(sql.IndexAddressableTable)
+			if !ok {
+				log.Fatalf("t not index addressable")
+			}
+			indexes, err := indexable.GetIndexes(ctx)
+			if err != nil {
+				log.Fatalf("%s\n", err)
+			}
+
+			scan := plan.NewResolvedTable(t, db, nil)
+			point := mustStaticIndexedAccessForResolvedTable(
+				plan.NewResolvedTable(t, db, nil),
+				sql.IndexLookup{
+					Index: indexes[0],
+					Ranges: sql.RangeCollection{
+						sql.Range{sql.ClosedRangeColumnExpr(rows/2, rows/2, types.Int64)},
+					},
+				})
+			// pruned reads every row but skips the large column
+			pruned := plan.NewResolvedTable(t.(*sqle.AlterableDoltTable).WithProjections([]string{"id", "n"}), db, nil)
+
+			if n := len(sortedRowStrings(ctx, scan)); n != rows {
+				log.Fatalf("%s: scan returned %d rows\n", name, n)
+			}
+			if v := firstRow(ctx, point)[1]; len(fmt.Sprintf("%s", v)) != size {
+				log.Fatalf("%s: point read returned a %d byte value\n", name, len(fmt.Sprintf("%s", v)))
+			}
+			scanNs := runOneBench(b, ctx, name+" scan", scan)
+			pointNs := runOneBench(b, ctx, name+" point read", point)
+			prunedNs := runOneBench(b, ctx, name+" pruned scan", pruned)
+
+			_, bytes := tableStorage(ctx, t)
+			report.WriteString(fmt.Sprintf("%-10d %-16s %5d %12d %12d %12d %12d %10d\n", size, typ, rows, insertNs, scanNs, pointNs, prunedNs, bytes))
+		}
+	}
+	log.Print(report.String())
+}
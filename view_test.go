@@ -0,0 +1,111 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/transform"
+	"log"
+	"strings"
+	"testing"
+)
+
+// viewDepth is the deepest chain of views in BenchmarkView. View vk
+// selects every column of v(k-1), and v1 selects every column of xy.
+const viewDepth = 5
+
+// BenchmarkView compares queries through views and chains of nested
+// views against the same query written against the base table, and
+// against the same nesting written as derived tables. Each query is
+// timed separately through the analyzer, which expands the views, and
+// through execution of the analyzed plan, and the report shows whether
+// filters and projections reached the table scan.
+func BenchmarkView(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int, z int, w int, key(y));")
+	insertRows(s, "xy", 10000, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d, %d", i, i, i, i)
+	})
+	s.WriteString("create view v1 as select * from xy;")
+	for k := 2; k <= viewDepth; k++ {
+		s.WriteString(fmt.Sprintf("create view v%d as select * from v%d;", k, k-1))
+	}
+	s.WriteString("create view vf as select * from xy where z < 5000;")
+	s.WriteString("create view vagg as select y, count(*) as c from xy group by y")
+	runSetup(e, ctx, s.String())
+
+	report := &strings.Builder{}
+	report.WriteString(fmt.Sprintf("%-40s %14s %14s %8s %8s %6s\n", "query", "analyze ns/op", "execute ns/op", "indexed", "columns", "rows"))
+	// run times |q| and returns its analyzed plan
+	run := func(name, q string) sql.Node {
+		n := logAnalyzedPlan(e, ctx, q)
+		rows := len(sortedRowStrings(ctx, n))
+		analyzeNs := runTimedBench(b, fmt.Sprintf("%s analyze", name), func(int) {
+			if _, err := e.AnalyzeQuery(ctx, q); err != nil {
+				log.Fatalf("analyzing query '%s': %s\n", q, err)
+			}
+		}, nil)
+		executeNs := runOneBench(b, ctx, fmt.Sprintf("%s execute", name), n)
+		report.WriteString(fmt.Sprintf("%-40s %14d %14d %8t %8d %6d\n", name, analyzeNs, executeNs, hasIndexedAccess(n), scannedColumns(n), rows))
+		return n
+	}
+
+	// depth 0 is the inlined query against xy, and every depth must
+	// return its rows. Each depth is also written as the same number of
+	// nested derived tables, to separate the cost of expanding the view
+	// definitions from the cost of analyzing the nested SubqueryAlias
+	// nodes.
+	for _, q := range []struct {
+		name  string
+		query string
+	}{
+		{name: "point", query: "select * from %s where x = 500"},
+		{name: "index range", query: "select * from %s where y between 100 and 199"},
+		{name: "projection", query: "select x from %s"},
+	} {
+		inlined := run(fmt.Sprintf("%s depth 0", q.name), fmt.Sprintf(q.query, "xy"))
+		derived := "xy"
+		for k := 1; k <= viewDepth; k++ {
+			name := fmt.Sprintf("%s depth %d", q.name, k)
+			checkEquivalent(ctx, name, inlined, run(name, fmt.Sprintf(q.query, fmt.Sprintf("v%d", k))))
+			derived = fmt.Sprintf("(select * from %s) d%d", derived, k)
+			name = fmt.Sprintf("%s derived depth %d", q.name, k)
+			checkEquivalent(ctx, name, inlined, run(name, fmt.Sprintf(q.query, derived)))
+		}
+	}
+
+	// an outer filter combined with the view's own filter, and an outer
+	// filter on the grouping column of an aggregating view
+	filtered := run("filtered view", "select * from vf where x < 10")
+	checkEquivalent(ctx, "filtered view", run("filtered view inlined", "select * from xy where z < 5000 and x < 10"), filtered)
+	aggregate := run("aggregate view", "select * from vagg where y = 5")
+	checkEquivalent(ctx, "aggregate view", run("aggregate view inlined", "select y, count(*) as c from xy where y = 5 group by y"), aggregate)
+
+	log.Print(report.String())
+}
+
+// scannedColumns returns the number of columns the first table access
+// in |n| reads.
+func scannedColumns(n sql.Node) int {
+	cols := 0
+	transform.Inspect(n, func(n sql.Node) bool {
+		var t sql.Table
+		switch n := n.(type) {
+		case *plan.ResolvedTable:
+			t = n.Table
+		case *plan.IndexedTableAccess:
+			t = n.Table
+		default:
+			return true
+		}
+		cols = len(t.Schema())
+		if pt, ok := t.(sql.ProjectedTable); ok && pt.Projections() != nil {
+			cols = len(pt.Projections())
+		}
+		return false
+	})
+	return cols
+}
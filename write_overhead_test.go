@@ -0,0 +1,146 @@
+package query_faq_toy
+
+import (
+	"fmt"
+	"github.com/dolthub/go-mysql-server/sql"
+	"log"
+	"strings"
+	"testing"
+)
+
+// writeOverheadRows is the number of rows each BenchmarkWriteOverhead
+// insert writes.
+const writeOverheadRows = 100
+
+// BenchmarkWriteOverhead inserts the same rows into tables that add
+// triggers, CHECK constraints or a generated column, and compares each
+// against the same insert into a plain table. Every table is t(id, a, b,
+// c), and the insert only sets id, a and b, so the extra logic can fill
+// c.
+func BenchmarkWriteOverhead(b *testing.B) {
+	e, ctx := setupMemDB()
+
+	columns := "id int primary key, a int, b int, c int"
+	// fillsC is set for the variants whose logic computes c, which are
+	// not timed if c is left NULL. |violation| is a row that breaks the
+	// table's CHECK constraints, which must fail to insert before the
+	// variant is timed.
+	variants := []struct {
+		name      string
+		setup     string
+		fillsC    bool
+		violation string
+	}{
+		{
+			name:  "no extra logic",
+			setup: fmt.Sprintf("create table t (%s)", columns),
+		},
+		{
+			name: "before trigger set",
+			setup: fmt.Sprintf("create table t (%s);", columns) +
+				"create trigger t_bi before insert on t for each row set new.c = new.a + new.b",
+			fillsC: true,
+		},
+		{
+			name: "before trigger subquery",
+			setup: fmt.Sprintf("create table t (%s);", columns) +
+				"create trigger t_bi before insert on t for each row set new.c = (select y from xy where x = new.a)",
+			fillsC: true,
+		},
+		{
+			name: "after trigger variable",
+			setup: fmt.Sprintf("create table t (%s);", columns) +
+				"create trigger t_ai after insert on t for each row set @inserted = @inserted + 1",
+		},
+		{
+			name: "after trigger insert",
+			setup: fmt.Sprintf("create table t (%s);", columns) +
+				"create trigger t_ai after insert on t for each row insert into audit values (new.id, new.a)",
+		},
+		{
+			name:      "check simple",
+			setup:     fmt.Sprintf("create table t (%s, check (a >= 0))", columns),
+			violation: "insert into t (id, a, b) values (-1, -1, 0)",
+		},
+		{
+			name:      "check complex",
+			setup:     fmt.Sprintf("create table t (%s, check (a >= 0 and b >= a and a + b < 1000000 and (c is null or c between 0 and 1000000) and b - a <> 999999))", columns),
+			violation: "insert into t (id, a, b) values (-1, 5, 1)",
+		},
+		{
+			name:      "check five constraints",
+			setup:     fmt.Sprintf("create table t (%s, check (a >= 0), check (b >= a), check (a + b < 1000000), check (c is null or c between 0 and 1000000), check (b - a <> 999999))", columns),
+			violation: "insert into t (id, a, b) values (-1, 5, 1)",
+		},
+		{
+			name:   "stored generated column",
+			setup:  "create table t (id int primary key, a int, b int, c int generated always as (a + b) stored)",
+			fillsC: true,
+		},
+	}
+
+	// xy is the lookup table of the subquery trigger, and audit is the
+	// target of the inserting trigger
+	s := &strings.Builder{}
+	s.WriteString("use test;")
+	s.WriteString("create table xy (x int primary key, y int);")
+	s.WriteString("create table audit (id int primary key, a int);")
+	insertRows(s, "xy", writeOverheadRows, func(i int) string {
+		return fmt.Sprintf("%d, %d", i, i*3)
+	})
+	s.WriteString("set @inserted = 0")
+	runSetup(e, ctx, s.String())
+
+	// the rows the inserting trigger writes, written by one statement
+	auditInsert := &strings.Builder{}
+	insertRows(auditInsert, "audit", writeOverheadRows, func(i int) string {
+		return fmt.Sprintf("%d, %d", i, i)
+	})
+	runOneQueryBench(b, e, ctx, fmt.Sprintf("insert %d audit rows in one statement", writeOverheadRows), strings.TrimSuffix(auditInsert.String(), ";\n"), "delete from audit")
+
+	insert := &strings.Builder{}
+	insertRows(insert, "t (id, a, b)", writeOverheadRows, func(i int) string {
+		return fmt.Sprintf("%d, %d, %d", i, i, i*2)
+	})
+	insertSQL := strings.TrimSuffix(insert.String(), ";\n")
+	reset := "delete from t; delete from audit; set @inserted = 0"
+
+	report := &strings.Builder{}
+	report.WriteString(fmt.Sprintf("%-24s %12s %12s %16s  %s\n", "table", "ns/op", "overhead", "ns per row", "c of row 5"))
+	var baseNs int64
+	for _, v := range variants {
+		runSetup(e, ctx, "drop table if exists t;"+v.setup)
+
+		if v.violation != "" {
+			sch, iter, err := e.Query(ctx, v.violation)
+			if err == nil {
+				_, err = sql.RowIterToRows(ctx, sch, iter)
+			}
+			if err == nil {
+				log.Fatalf("%s: '%s' broke the CHECK constraint and succeeded\n", v.name, v.violation)
+			}
+			log.Printf("%s: '%s' fails: %s\n", v.name, v.violation, err)
+		}
+
+		// one untimed insert shows what the extra logic wrote
+		runQuery(e, ctx, insertSQL)
+		c := runQuery(e, ctx, "select c from t where id = 5")[0][0]
+		audit := runQuery(e, ctx, "select count(*) from audit")[0][0]
+		log.Printf("%s: c of row 5 is %v, audit has %v rows, @inserted is %v\n", v.name, c, audit, runQuery(e, ctx, "select @inserted")[0][0])
+		runSetup(e, ctx, reset)
+		name := fmt.Sprintf("insert %d rows %s", writeOverheadRows, v.name)
+		if v.fillsC && c == nil {
+			b.Run(name, func(b *testing.B) {
+				b.Skipf("c of row 5 is NULL, so the insert does not run the logic that computes c")
+			})
+			continue
+		}
+
+		nsOp := runOneQueryBench(b, e, ctx, name, insertSQL, reset)
+		if baseNs == 0 {
+			baseNs = nsOp
+		}
+		report.WriteString(fmt.Sprintf("%-24s %12d %11.2fx %16d  %v\n", v.name, nsOp, float64(nsOp)/float64(baseNs), (nsOp-baseNs)/writeOverheadRows, c))
+	}
+	log.Print(report.String())
+}